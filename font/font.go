@@ -18,8 +18,8 @@ import (
 )
 
 func init() {
-	// Initializes the font library. The win.Close function calls SDL_Quit, which
-	// also quits the font library.
+	// Initializes the font library, which is independent of the SDL subsystems
+	// and therefore remains initialized when win.Close is called.
 	if C.TTF_Init() != 0 {
 		log.Fatalln(getError())
 	}
@@ -0,0 +1,177 @@
+package win
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/draw"
+	"image/png"
+	"io"
+	"os"
+	"time"
+)
+
+// An apngEncoder encodes frames as an animated PNG image. Each frame is
+// encoded using image/png, after which its image data is repackaged into the
+// frame chunks of the APNG format.
+//
+// The dimensions of the animation are given by the first frame; subsequent
+// frames are cropped or padded to fit.
+type apngEncoder struct {
+	// Output file.
+	f *os.File
+	// The bounds of the animation.
+	bounds image.Rectangle
+	// The offset of the acTL chunk in the output file, which is updated with
+	// the final frame count once the encoding is finished.
+	actlOff int64
+	// The number of encoded frames.
+	n uint32
+	// The sequence number of the next fcTL or fdAT chunk.
+	seq uint32
+	// The first error encountered while writing.
+	err error
+}
+
+// pngHeader is the signature of PNG files.
+const pngHeader = "\x89PNG\r\n\x1a\n"
+
+// encode adds the frame to the animation.
+func (enc *apngEncoder) encode(img *image.NRGBA, d time.Duration) (err error) {
+	if enc.n == 0 {
+		enc.bounds = img.Bounds()
+	}
+	// Window frames are opaque, which also makes image/png use the same color
+	// type for every frame.
+	frame := image.NewNRGBA(image.Rect(0, 0, enc.bounds.Dx(), enc.bounds.Dy()))
+	draw.Draw(frame, frame.Bounds(), img, img.Bounds().Min, draw.Src)
+	for i := 3; i < len(frame.Pix); i += 4 {
+		frame.Pix[i] = 0xFF
+	}
+	pngEnc := &png.Encoder{CompressionLevel: png.BestSpeed}
+	buf := new(bytes.Buffer)
+	err = pngEnc.Encode(buf, frame)
+	if err != nil {
+		return err
+	}
+	ihdr, data, err := pngChunks(buf.Bytes())
+	if err != nil {
+		return err
+	}
+
+	if enc.n == 0 {
+		// Write the PNG signature, the IHDR chunk and a placeholder acTL chunk.
+		enc.write([]byte(pngHeader))
+		enc.writeChunk("IHDR", ihdr)
+		enc.actlOff = int64(len(pngHeader) + 12 + len(ihdr))
+		enc.writeChunk("acTL", make([]byte, 8))
+	}
+
+	// Write the fcTL chunk of the frame. The delay is stored as a fraction of
+	// seconds, in milliseconds.
+	delay := d / time.Millisecond
+	if delay > 0xFFFF {
+		delay = 0xFFFF
+	}
+	fctl := make([]byte, 26)
+	binary.BigEndian.PutUint32(fctl[0:], enc.seq)
+	binary.BigEndian.PutUint32(fctl[4:], uint32(enc.bounds.Dx()))
+	binary.BigEndian.PutUint32(fctl[8:], uint32(enc.bounds.Dy()))
+	// x and y offsets are 0.
+	binary.BigEndian.PutUint16(fctl[20:], uint16(delay))
+	binary.BigEndian.PutUint16(fctl[22:], 1000)
+	// Dispose and blend operations are APNG_DISPOSE_OP_NONE and
+	// APNG_BLEND_OP_SOURCE.
+	enc.writeChunk("fcTL", fctl)
+	enc.seq++
+
+	// The image data of the first frame is stored in an IDAT chunk, which
+	// makes it the default image of decoders unaware of APNG. The image data
+	// of subsequent frames is stored in fdAT chunks.
+	if enc.n == 0 {
+		enc.writeChunk("IDAT", data)
+	} else {
+		fdat := make([]byte, 4+len(data))
+		binary.BigEndian.PutUint32(fdat, enc.seq)
+		copy(fdat[4:], data)
+		enc.writeChunk("fdAT", fdat)
+		enc.seq++
+	}
+	enc.n++
+	return enc.err
+}
+
+// close writes the final frame count and the IEND chunk, and closes the output
+// file. Nothing is written if no frames were recorded, the same as for the
+// other record formats.
+func (enc *apngEncoder) close() (err error) {
+	defer enc.f.Close()
+	if enc.n == 0 {
+		return enc.f.Close()
+	}
+	enc.writeChunk("IEND", nil)
+	if enc.err != nil {
+		return enc.err
+	}
+	// Update the acTL chunk with the final frame count; the animation loops
+	// indefinitely.
+	actl := make([]byte, 8)
+	binary.BigEndian.PutUint32(actl, enc.n)
+	if _, err := enc.f.Seek(enc.actlOff, io.SeekStart); err != nil {
+		return err
+	}
+	enc.writeChunk("acTL", actl)
+	if enc.err != nil {
+		return enc.err
+	}
+	return enc.f.Close()
+}
+
+// write writes buf to the output file.
+func (enc *apngEncoder) write(buf []byte) {
+	if enc.err != nil {
+		return
+	}
+	_, enc.err = enc.f.Write(buf)
+}
+
+// writeChunk writes a PNG chunk of the provided type and data to the output
+// file.
+func (enc *apngEncoder) writeChunk(typ string, data []byte) {
+	buf := make([]byte, 12+len(data))
+	binary.BigEndian.PutUint32(buf, uint32(len(data)))
+	copy(buf[4:], typ)
+	copy(buf[8:], data)
+	crc := crc32.ChecksumIEEE(buf[4 : 8+len(data)])
+	binary.BigEndian.PutUint32(buf[8+len(data):], crc)
+	enc.write(buf)
+}
+
+// pngChunks parses the provided PNG image and returns the data of its IHDR
+// chunk and the concatenated data of its IDAT chunks.
+func pngChunks(buf []byte) (ihdr, data []byte, err error) {
+	if !bytes.HasPrefix(buf, []byte(pngHeader)) {
+		return nil, nil, errors.New("win.pngChunks: invalid PNG signature")
+	}
+	buf = buf[len(pngHeader):]
+	for len(buf) >= 12 {
+		n := int(binary.BigEndian.Uint32(buf))
+		if 12+n > len(buf) {
+			break
+		}
+		typ := string(buf[4:8])
+		switch typ {
+		case "IHDR":
+			ihdr = buf[8 : 8+n]
+		case "IDAT":
+			data = append(data, buf[8:8+n]...)
+		}
+		buf = buf[12+n:]
+	}
+	if ihdr == nil || data == nil {
+		return nil, nil, errors.New("win.pngChunks: missing IHDR or IDAT chunk")
+	}
+	return ihdr, data, nil
+}
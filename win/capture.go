@@ -0,0 +1,282 @@
+package win
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// SaveScreenshot saves the current contents of the window image to the
+// provided PNG file.
+func SaveScreenshot(pngPath string) (err error) {
+	img, err := Snapshot()
+	if err != nil {
+		return err
+	}
+	return writePNG(pngPath, img)
+}
+
+// writePNG writes img to the provided PNG file.
+func writePNG(pngPath string, img image.Image) (err error) {
+	f, err := os.Create(pngPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	err = png.Encode(f, img)
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// RecordFormat specifies the output format of a Recorder.
+type RecordFormat int
+
+// Record formats.
+const (
+	// RecordPNG stores each frame as a separate PNG file, named
+	// frame_000000.png, frame_000001.png, etc. The output path is a directory.
+	RecordPNG RecordFormat = iota
+	// RecordGIF stores the frames as an animated GIF image, using a
+	// quantized palette of 256 colors for each frame. The image is written
+	// once the recorder is closed.
+	RecordGIF
+	// RecordAPNG stores the frames as an animated PNG image.
+	RecordAPNG
+	// RecordY4M streams the frames as raw YUV 4:2:0 video in the YUV4MPEG2
+	// format, which may be piped to ffmpeg; see NewStreamRecorder. The output
+	// is written at the frame rate of the recorder regardless of the actual
+	// capture times.
+	RecordY4M
+)
+
+// A Recorder captures the contents of the window image on each call to Update
+// or UpdateRects. The frames are encoded on a background goroutine, so that
+// recording does not stall the rendering.
+type Recorder struct {
+	// Captured frames pending encoding.
+	frames chan recordFrame
+	// Receives the result of the encoder once all frames have been encoded.
+	done chan error
+	// Specifies whether the recorder has been closed.
+	closed bool
+	// The number of frames dropped as the encoder was unable to keep up.
+	dropped int
+}
+
+// A recordFrame is a captured frame together with its capture time.
+type recordFrame struct {
+	img *image.NRGBA
+	t   time.Time
+}
+
+// recorders holds the active recorders.
+var recorders []*Recorder
+
+// recordBuffer is the maximum number of captured frames which may be pending
+// encoding before frames are dropped.
+const recordBuffer = 64
+
+// NewRecorder starts to record the window to the provided output path, using
+// the specified format. The frame rate determines the frame duration of the
+// last frame, and the frame rate of RecordY4M output.
+//
+// Note: The Close method of the recorder must be called when finished
+// recording.
+func NewRecorder(outPath string, format RecordFormat, fps int) (rec *Recorder, err error) {
+	if fps <= 0 {
+		return nil, fmt.Errorf("win.NewRecorder: invalid frame rate %d", fps)
+	}
+	var enc frameEncoder
+	switch format {
+	case RecordPNG:
+		err = os.MkdirAll(outPath, 0755)
+		if err != nil {
+			return nil, err
+		}
+		enc = &pngSeqEncoder{dir: outPath}
+	case RecordGIF, RecordAPNG, RecordY4M:
+		f, err := os.Create(outPath)
+		if err != nil {
+			return nil, err
+		}
+		switch format {
+		case RecordGIF:
+			enc = &gifEncoder{f: f}
+		case RecordAPNG:
+			enc = &apngEncoder{f: f}
+		case RecordY4M:
+			enc = &y4mEncoder{f: f, fps: fps}
+		}
+	default:
+		return nil, fmt.Errorf("win.NewRecorder: invalid record format %d", format)
+	}
+	return startRecorder(enc, fps), nil
+}
+
+// NewStreamRecorder starts to record the window to the provided output
+// stream, e.g. the standard input of an ffmpeg process, using the specified
+// format. Only the RecordGIF and RecordY4M formats may be streamed. The frame
+// rate determines the frame duration of the last frame, and the frame rate of
+// RecordY4M output.
+//
+// The output stream is not closed by the recorder.
+//
+// Note: The Close method of the recorder must be called when finished
+// recording.
+func NewStreamRecorder(w io.Writer, format RecordFormat, fps int) (rec *Recorder, err error) {
+	if fps <= 0 {
+		return nil, fmt.Errorf("win.NewStreamRecorder: invalid frame rate %d", fps)
+	}
+	var enc frameEncoder
+	switch format {
+	case RecordGIF:
+		enc = &gifEncoder{f: nopCloser{w}}
+	case RecordY4M:
+		enc = &y4mEncoder{f: nopCloser{w}, fps: fps}
+	default:
+		return nil, fmt.Errorf("win.NewStreamRecorder: record format %d may not be streamed", format)
+	}
+	return startRecorder(enc, fps), nil
+}
+
+// nopCloser is an output stream with a no-op Close method.
+type nopCloser struct {
+	io.Writer
+}
+
+// Close does nothing.
+func (nopCloser) Close() error {
+	return nil
+}
+
+// startRecorder starts a recorder which encodes the captured frames using enc
+// on a background goroutine.
+func startRecorder(enc frameEncoder, fps int) (rec *Recorder) {
+	frames := make(chan recordFrame, recordBuffer)
+	rec = &Recorder{
+		frames: frames,
+		done:   make(chan error, 1),
+	}
+	go rec.encode(frames, enc, time.Second/time.Duration(fps))
+	recorders = append(recorders, rec)
+	return rec
+}
+
+// Close stops the recording and waits for all captured frames to be encoded.
+// It returns the first error encountered while encoding, if any. The output is
+// left empty if no frames were captured.
+func (rec *Recorder) Close() (err error) {
+	for i, r := range recorders {
+		if r == rec {
+			recorders = append(recorders[:i], recorders[i+1:]...)
+			break
+		}
+	}
+	if rec.closed {
+		return errors.New("win.Recorder.Close: recorder already closed")
+	}
+	rec.closed = true
+	close(rec.frames)
+	return <-rec.done
+}
+
+// Dropped returns the number of frames which have been dropped, as the encoder
+// was unable to keep up with the rate of updates.
+func (rec *Recorder) Dropped() int {
+	return rec.dropped
+}
+
+// encode encodes the captured frames until the frames channel is closed. The
+// duration of each frame is known once the following frame has been captured;
+// the last frame lasts for the provided frame duration.
+func (rec *Recorder) encode(frames <-chan recordFrame, enc frameEncoder, frameDur time.Duration) {
+	var err error
+	var pending *recordFrame
+	for frame := range frames {
+		frame := frame
+		if pending != nil && err == nil {
+			err = enc.encode(pending.img, frame.t.Sub(pending.t))
+		}
+		pending = &frame
+	}
+	if pending != nil && err == nil {
+		err = enc.encode(pending.img, frameDur)
+	}
+	if cerr := enc.close(); err == nil {
+		err = cerr
+	}
+	rec.done <- err
+}
+
+// captureFrames captures the contents of the window image for each active
+// recorder.
+func captureFrames() (err error) {
+	if len(recorders) == 0 {
+		return nil
+	}
+	// Drop the frame without capturing it if no encoder is able to keep up.
+	full := true
+	for _, rec := range recorders {
+		if len(rec.frames) < cap(rec.frames) {
+			full = false
+			break
+		}
+	}
+	if full {
+		for _, rec := range recorders {
+			rec.dropped++
+		}
+		return nil
+	}
+	img, err := Snapshot()
+	if err != nil {
+		return err
+	}
+	frame := recordFrame{img: img, t: time.Now()}
+	for _, rec := range recorders {
+		// Drop the frame rather than stalling the rendering if the encoder is
+		// unable to keep up.
+		select {
+		case rec.frames <- frame:
+		default:
+			rec.dropped++
+		}
+	}
+	return nil
+}
+
+// A frameEncoder encodes a sequence of frames.
+type frameEncoder interface {
+	// encode encodes a frame which lasts for the provided duration. The frame
+	// must not be modified by the encoder, as it may be shared.
+	encode(img *image.NRGBA, d time.Duration) error
+	// close finishes the encoding.
+	close() error
+}
+
+// A pngSeqEncoder encodes each frame as a separate PNG file.
+type pngSeqEncoder struct {
+	// Output directory.
+	dir string
+	// The number of encoded frames.
+	n int
+}
+
+// encode encodes the frame to the next PNG file of the sequence.
+func (enc *pngSeqEncoder) encode(img *image.NRGBA, d time.Duration) (err error) {
+	pngPath := filepath.Join(enc.dir, fmt.Sprintf("frame_%06d.png", enc.n))
+	enc.n++
+	return writePNG(pngPath, img)
+}
+
+// close finishes the encoding.
+func (enc *pngSeqEncoder) close() (err error) {
+	return nil
+}
@@ -0,0 +1,228 @@
+package win
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStreamRecorder(t *testing.T) {
+	openHeadless(t, 16, 8)
+	screen, err := Screen()
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := new(bytes.Buffer)
+	rec, err := NewStreamRecorder(buf, RecordY4M, 30)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const n = 3
+	for i := 0; i < n; i++ {
+		if err := screen.Fill(color.NRGBA{R: uint8(i * 100), A: 0xFF}); err != nil {
+			t.Fatal(err)
+		}
+		if err := Update(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := rec.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := rec.Close(); err == nil {
+		t.Error("second Close; expected error, got nil")
+	}
+	if !bytes.HasPrefix(buf.Bytes(), []byte("YUV4MPEG2 W16 H8 ")) {
+		t.Errorf("invalid YUV4MPEG2 header %q", bytes.SplitN(buf.Bytes(), []byte("\n"), 2)[0])
+	}
+	if got := bytes.Count(buf.Bytes(), []byte("FRAME\n")); got != n {
+		t.Errorf("number of frames mismatch; expected %d, got %d", n, got)
+	}
+	if _, err := NewStreamRecorder(buf, RecordAPNG, 30); err == nil {
+		t.Error("streamed APNG recording; expected error, got nil")
+	}
+}
+
+func TestRecorderCloseImmediately(t *testing.T) {
+	// Closing a recorder before its encoder has started must not block.
+	for i := 0; i < 100; i++ {
+		rec, err := NewStreamRecorder(new(bytes.Buffer), RecordY4M, 30)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := rec.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// recordFrames fills the window with n different colors, updating the window
+// after each fill, with a pause of d between updates.
+func recordFrames(t *testing.T, n int, d time.Duration) {
+	screen, err := Screen()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			time.Sleep(d)
+		}
+		if err := screen.Fill(color.NRGBA{R: uint8(i * 100), B: 0xFF, A: 0xFF}); err != nil {
+			t.Fatal(err)
+		}
+		if err := Update(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestRecordGIF(t *testing.T) {
+	openHeadless(t, 16, 8)
+	buf := new(bytes.Buffer)
+	rec, err := NewStreamRecorder(buf, RecordGIF, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const n = 3
+	recordFrames(t, n, 50*time.Millisecond)
+	if err := rec.Close(); err != nil {
+		t.Fatal(err)
+	}
+	anim, err := gif.DecodeAll(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(anim.Image) != n {
+		t.Fatalf("number of frames mismatch; expected %d, got %d", n, len(anim.Image))
+	}
+	// The delays are measured in 100ths of a second; the last frame lasts for
+	// the frame duration of the recorder.
+	for i, delay := range anim.Delay[:n-1] {
+		if delay < 5 {
+			t.Errorf("frame %d: delay mismatch; expected at least 5, got %d", i, delay)
+		}
+	}
+	if delay := anim.Delay[n-1]; delay != 5 {
+		t.Errorf("frame %d: delay mismatch; expected 5, got %d", n-1, delay)
+	}
+	for i, frame := range anim.Image {
+		want := color.NRGBA{R: uint8(i * 100), B: 0xFF, A: 0xFF}
+		got := color.NRGBAModel.Convert(frame.At(0, 0)).(color.NRGBA)
+		if colorDiff(want, got) > 8 {
+			t.Errorf("frame %d: pixel (0, 0) mismatch; expected %v, got %v", i, want, got)
+		}
+	}
+}
+
+func TestRecordAPNG(t *testing.T) {
+	openHeadless(t, 16, 8)
+	outPath := filepath.Join(t.TempDir(), "out.png")
+	rec, err := NewRecorder(outPath, RecordAPNG, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const n = 3
+	recordFrames(t, n, 10*time.Millisecond)
+	if err := rec.Close(); err != nil {
+		t.Fatal(err)
+	}
+	buf, err := os.ReadFile(outPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Decoders unaware of APNG see the first frame.
+	img, err := png.Decode(bytes.NewReader(buf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := color.NRGBAModel.Convert(img.At(0, 0)); got != (color.NRGBA{B: 0xFF, A: 0xFF}) {
+		t.Errorf("default image: pixel (0, 0) mismatch; expected blue, got %v", got)
+	}
+
+	// Walk the chunks, validating their checksums and sequence numbers.
+	if !bytes.HasPrefix(buf, []byte(pngHeader)) {
+		t.Fatal("invalid PNG signature")
+	}
+	buf = buf[len(pngHeader):]
+	var types []string
+	var frames, seq uint32
+	var delays []uint16
+	for len(buf) > 0 {
+		if len(buf) < 12 {
+			t.Fatalf("truncated chunk after %q", types)
+		}
+		size := int(binary.BigEndian.Uint32(buf))
+		if 12+size > len(buf) {
+			t.Fatalf("truncated chunk after %q", types)
+		}
+		typ, data := string(buf[4:8]), buf[8:8+size]
+		crc := binary.BigEndian.Uint32(buf[8+size:])
+		if want := crc32.ChecksumIEEE(buf[4 : 8+size]); crc != want {
+			t.Errorf("%s chunk: CRC mismatch; expected %08X, got %08X", typ, want, crc)
+		}
+		switch typ {
+		case "acTL":
+			frames = binary.BigEndian.Uint32(data)
+		case "fcTL", "fdAT":
+			if got := binary.BigEndian.Uint32(data); got != seq {
+				t.Errorf("%s chunk: sequence number mismatch; expected %d, got %d", typ, seq, got)
+			}
+			seq++
+			if typ == "fcTL" {
+				delays = append(delays, binary.BigEndian.Uint16(data[20:]))
+			}
+		}
+		types = append(types, typ)
+		buf = buf[12+size:]
+	}
+	if frames != n {
+		t.Errorf("acTL frame count mismatch; expected %d, got %d", n, frames)
+	}
+	if len(delays) != n {
+		t.Fatalf("number of fcTL chunks mismatch; expected %d, got %d", n, len(delays))
+	}
+	// The delays are measured in milliseconds.
+	if delay := delays[n-1]; delay != 50 {
+		t.Errorf("frame %d: delay mismatch; expected 50, got %d", n-1, delay)
+	}
+	// The first frame is stored in an IDAT chunk and the others in fdAT chunks.
+	want := []string{"IHDR", "acTL", "fcTL", "IDAT", "fcTL", "fdAT", "fcTL", "fdAT", "IEND"}
+	if len(types) != len(want) {
+		t.Fatalf("chunks mismatch; expected %q, got %q", want, types)
+	}
+	for i := range want {
+		if types[i] != want[i] {
+			t.Fatalf("chunks mismatch; expected %q, got %q", want, types)
+		}
+	}
+}
+
+func TestRecordNoFrames(t *testing.T) {
+	// All formats leave the output empty if no frames were recorded.
+	dir := t.TempDir()
+	for _, format := range []RecordFormat{RecordGIF, RecordAPNG, RecordY4M} {
+		outPath := filepath.Join(dir, "out")
+		rec, err := NewRecorder(outPath, format, 30)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := rec.Close(); err != nil {
+			t.Errorf("format %d: %v", format, err)
+		}
+		buf, err := os.ReadFile(outPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(buf) != 0 {
+			t.Errorf("format %d: output size mismatch; expected 0, got %d", format, len(buf))
+		}
+	}
+}
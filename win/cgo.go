@@ -1,6 +1,7 @@
 package win
 
 // #cgo pkg-config: sdl2
+// #include <stdlib.h>
 // #include <SDL2/SDL.h>
 import "C"
 
@@ -24,6 +25,53 @@ func cRect(rect image.Rectangle) (cRect *C.SDL_Rect) {
 	return cRect
 }
 
+// goRect converts a C SDL_Rect to a Go image.Rectangle.
+func goRect(rect C.SDL_Rect) image.Rectangle {
+	return image.Rect(int(rect.x), int(rect.y), int(rect.x+rect.w), int(rect.y+rect.h))
+}
+
+// cBool converts a Go bool to a C SDL_bool.
+func cBool(b bool) C.SDL_bool {
+	if b {
+		return C.SDL_TRUE
+	}
+	return C.SDL_FALSE
+}
+
+// setHint sets the value of the provided SDL configuration hint.
+func setHint(name, value string) {
+	cName := C.CString(name)
+	defer C.free(unsafe.Pointer(cName))
+	cValue := C.CString(value)
+	defer C.free(unsafe.Pointer(cValue))
+	C.SDL_SetHint(cName, cValue)
+}
+
+// getHint returns the value of the provided SDL configuration hint, and
+// whether it is set.
+func getHint(name string) (value string, ok bool) {
+	cName := C.CString(name)
+	defer C.free(unsafe.Pointer(cName))
+	cValue := C.SDL_GetHint(cName)
+	if cValue == nil {
+		return "", false
+	}
+	return C.GoString(cValue), true
+}
+
+// restoreHint restores the provided SDL configuration hint to a value returned
+// by getHint.
+func restoreHint(name, value string, ok bool) {
+	if ok {
+		setHint(name, value)
+		return
+	}
+	cName := C.CString(name)
+	defer C.free(unsafe.Pointer(cName))
+	// A hint with a NULL value is treated as unset.
+	C.SDL_SetHint(cName, nil)
+}
+
 // getError returns the last error message.
 func getError() (err error) {
 	return errors.New(C.GoString(C.SDL_GetError()))
@@ -0,0 +1,40 @@
+package win
+
+// #cgo pkg-config: sdl2
+// #include <stdlib.h>
+// #include <SDL2/SDL.h>
+import "C"
+
+import (
+	"unsafe"
+)
+
+// ClipboardUpdate is a clipboard event which is triggered when the contents of
+// the clipboard have changed.
+type ClipboardUpdate struct{}
+
+// ClipboardText returns the text contents of the clipboard.
+func ClipboardText() (text string, err error) {
+	cText := C.SDL_GetClipboardText()
+	if cText == nil {
+		return "", getError()
+	}
+	defer C.SDL_free(unsafe.Pointer(cText))
+	return C.GoString(cText), nil
+}
+
+// SetClipboardText sets the text contents of the clipboard.
+func SetClipboardText(text string) (err error) {
+	cText := C.CString(text)
+	defer C.free(unsafe.Pointer(cText))
+	if C.SDL_SetClipboardText(cText) != 0 {
+		return getError()
+	}
+	return nil
+}
+
+// HasClipboardText returns true if the clipboard contains non-empty text, and
+// false otherwise.
+func HasClipboardText() bool {
+	return C.SDL_HasClipboardText() == C.SDL_TRUE
+}
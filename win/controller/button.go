@@ -0,0 +1,47 @@
+package controller
+
+// #include <SDL2/SDL.h>
+import "C"
+
+// Button specifies a button of a game controller. The button names are based
+// on the layout of an Xbox controller.
+type Button int
+
+// Controller buttons.
+const (
+	ButtonA             Button = C.SDL_CONTROLLER_BUTTON_A
+	ButtonB             Button = C.SDL_CONTROLLER_BUTTON_B
+	ButtonX             Button = C.SDL_CONTROLLER_BUTTON_X
+	ButtonY             Button = C.SDL_CONTROLLER_BUTTON_Y
+	ButtonBack          Button = C.SDL_CONTROLLER_BUTTON_BACK
+	ButtonGuide         Button = C.SDL_CONTROLLER_BUTTON_GUIDE
+	ButtonStart         Button = C.SDL_CONTROLLER_BUTTON_START
+	ButtonLeftStick     Button = C.SDL_CONTROLLER_BUTTON_LEFTSTICK
+	ButtonRightStick    Button = C.SDL_CONTROLLER_BUTTON_RIGHTSTICK
+	ButtonLeftShoulder  Button = C.SDL_CONTROLLER_BUTTON_LEFTSHOULDER
+	ButtonRightShoulder Button = C.SDL_CONTROLLER_BUTTON_RIGHTSHOULDER
+	ButtonDPadUp        Button = C.SDL_CONTROLLER_BUTTON_DPAD_UP
+	ButtonDPadDown      Button = C.SDL_CONTROLLER_BUTTON_DPAD_DOWN
+	ButtonDPadLeft      Button = C.SDL_CONTROLLER_BUTTON_DPAD_LEFT
+	ButtonDPadRight     Button = C.SDL_CONTROLLER_BUTTON_DPAD_RIGHT
+)
+
+// Axis specifies an axis of a game controller.
+type Axis int
+
+// Controller axes.
+const (
+	AxisLeftX        Axis = C.SDL_CONTROLLER_AXIS_LEFTX
+	AxisLeftY        Axis = C.SDL_CONTROLLER_AXIS_LEFTY
+	AxisRightX       Axis = C.SDL_CONTROLLER_AXIS_RIGHTX
+	AxisRightY       Axis = C.SDL_CONTROLLER_AXIS_RIGHTY
+	AxisTriggerLeft  Axis = C.SDL_CONTROLLER_AXIS_TRIGGERLEFT
+	AxisTriggerRight Axis = C.SDL_CONTROLLER_AXIS_TRIGGERRIGHT
+)
+
+// numButtons and numAxes are the number of buttons and axes of a game
+// controller.
+const (
+	numButtons = C.SDL_CONTROLLER_BUTTON_MAX
+	numAxes    = C.SDL_CONTROLLER_AXIS_MAX
+)
@@ -0,0 +1,25 @@
+package controller
+
+// #cgo pkg-config: sdl2
+// #include <stdlib.h>
+// #include <SDL2/SDL.h>
+import "C"
+
+import (
+	"errors"
+	"unsafe"
+)
+
+// getError returns the last error message.
+func getError() (err error) {
+	return errors.New(C.GoString(C.SDL_GetError()))
+}
+
+// setHint sets the value of the provided SDL configuration hint.
+func setHint(name, value string) {
+	cName := C.CString(name)
+	defer C.free(unsafe.Pointer(cName))
+	cValue := C.CString(value)
+	defer C.free(unsafe.Pointer(cValue))
+	C.SDL_SetHint(cName, cValue)
+}
@@ -0,0 +1,200 @@
+// Package controller provides support for game controller input. Controller
+// events are delivered through win.PollEvent once the package is imported.
+//
+// The library uses a small subset of the features provided by SDL version 2.0.
+// The game controller subsystem is automatically initialized when imported.
+// Therefore the Quit function must be called when finished using the library.
+package controller
+
+// #cgo pkg-config: sdl2
+// #include <stdlib.h>
+// #include <SDL2/SDL.h>
+import "C"
+
+import (
+	"io"
+	"io/ioutil"
+	"log"
+	"unsafe"
+
+	"github.com/mewmew/sdl/win"
+)
+
+func init() {
+	err := initController()
+	if err != nil {
+		log.Fatalln(err)
+	}
+	win.RegisterEventTranslator(goEvent)
+	win.RegisterEventCodec(win.EventCodec{
+		Name:   "controller",
+		Encode: encodeEvent,
+		Decode: decodeEvent,
+	})
+}
+
+// initController initializes the game controller subsystem.
+//
+// Note: The Quit function must be called when finished using the controller
+// library.
+func initController() (err error) {
+	if C.SDL_InitSubSystem(C.SDL_INIT_GAMECONTROLLER) != 0 {
+		return getError()
+	}
+	return nil
+}
+
+// Quit quits the game controller subsystem.
+//
+// Note: The Quit function must be called when finished using the controller
+// library.
+func Quit() {
+	C.SDL_QuitSubSystem(C.SDL_INIT_GAMECONTROLLER)
+}
+
+// An ID uniquely identifies an opened controller for as long as it remains
+// connected. It is used by controller events to identify their controller.
+type ID int32
+
+// A Controller is a game controller.
+type Controller struct {
+	// C game controller pointer.
+	c *C.SDL_GameController
+}
+
+// opened is a map from the IDs of opened controllers to the controllers.
+var opened = make(map[ID]*Controller)
+
+// Count returns the number of connected joysticks, some of which may not be
+// supported as game controllers. See IsController.
+func Count() (n int, err error) {
+	num := C.SDL_NumJoysticks()
+	if num < 0 {
+		return 0, getError()
+	}
+	return int(num), nil
+}
+
+// IsController returns true if the joystick of the provided device index is
+// supported as a game controller, and false otherwise.
+func IsController(index int) bool {
+	return C.SDL_IsGameController(C.int(index)) == C.SDL_TRUE
+}
+
+// Open opens the game controller of the provided device index, which ranges
+// from 0 to Count()-1. The device index of newly connected controllers is
+// reported by Added events.
+//
+// Note: The Close method of the controller should be called when finished
+// using it.
+func Open(index int) (ctrl *Controller, err error) {
+	ctrl = new(Controller)
+	ctrl.c = C.SDL_GameControllerOpen(C.int(index))
+	if ctrl.c == nil {
+		return nil, getError()
+	}
+	opened[ctrl.ID()] = ctrl
+	return ctrl, nil
+}
+
+// FromID returns the opened controller of the provided ID, or nil if no such
+// controller exists.
+func FromID(id ID) *Controller {
+	return opened[id]
+}
+
+// Close closes the controller.
+func (ctrl *Controller) Close() {
+	delete(opened, ctrl.ID())
+	C.SDL_GameControllerClose(ctrl.c)
+}
+
+// ID returns the ID of the controller.
+func (ctrl *Controller) ID() ID {
+	joystick := C.SDL_GameControllerGetJoystick(ctrl.c)
+	return ID(C.SDL_JoystickInstanceID(joystick))
+}
+
+// Name returns the name of the controller.
+func (ctrl *Controller) Name() string {
+	return C.GoString(C.SDL_GameControllerName(ctrl.c))
+}
+
+// Attached returns true if the controller is still connected, and false
+// otherwise.
+func (ctrl *Controller) Attached() bool {
+	return C.SDL_GameControllerGetAttached(ctrl.c) == C.SDL_TRUE
+}
+
+// IsDown returns true if the provided button of the controller is held down,
+// and false otherwise.
+func (ctrl *Controller) IsDown(button Button) bool {
+	return C.SDL_GameControllerGetButton(ctrl.c, C.SDL_GameControllerButton(button)) == 1
+}
+
+// Axis returns the current value of the provided axis of the controller. The
+// value of thumbstick axes ranges from -32768 to 32767, and the value of
+// trigger axes ranges from 0 to 32767.
+func (ctrl *Controller) Axis(axis Axis) int16 {
+	return int16(C.SDL_GameControllerGetAxis(ctrl.c, C.SDL_GameControllerAxis(axis)))
+}
+
+// AddMapping adds a controller mapping, in the format of the SDL game
+// controller database, e.g.:
+//    341a3608000000000000504944564944,Afterglow PS3 Controller,a:b1,b:b2,...
+//
+// An existing mapping of the same controller GUID is replaced.
+func AddMapping(mapping string) (err error) {
+	cMapping := C.CString(mapping)
+	defer C.free(unsafe.Pointer(cMapping))
+	if C.SDL_GameControllerAddMapping(cMapping) < 0 {
+		return getError()
+	}
+	return nil
+}
+
+// AddMappings reads a controller mapping database, such as
+// gamecontrollerdb.txt, from r and adds its mappings for the current platform.
+// It returns the number of added mappings.
+func AddMappings(r io.Reader) (n int, err error) {
+	buf, err := ioutil.ReadAll(r)
+	if err != nil {
+		return 0, err
+	}
+	if len(buf) == 0 {
+		return 0, nil
+	}
+	// The database is copied to C memory, as it is accessed through the RW
+	// stream.
+	cBuf := C.CBytes(buf)
+	defer C.free(cBuf)
+	rw := C.SDL_RWFromConstMem(cBuf, C.int(len(buf)))
+	if rw == nil {
+		return 0, getError()
+	}
+	// The RW stream is freed by SDL_GameControllerAddMappingsFromRW.
+	num := C.SDL_GameControllerAddMappingsFromRW(rw, 1)
+	if num < 0 {
+		return 0, getError()
+	}
+	return int(num), nil
+}
+
+// AddMappingsFile reads a controller mapping database, such as
+// gamecontrollerdb.txt, from the provided file and adds its mappings for the
+// current platform. It returns the number of added mappings.
+func AddMappingsFile(dbPath string) (n int, err error) {
+	cPath := C.CString(dbPath)
+	defer C.free(unsafe.Pointer(cPath))
+	cMode := C.CString("rb")
+	defer C.free(unsafe.Pointer(cMode))
+	rw := C.SDL_RWFromFile(cPath, cMode)
+	if rw == nil {
+		return 0, getError()
+	}
+	num := C.SDL_GameControllerAddMappingsFromRW(rw, 1)
+	if num < 0 {
+		return 0, getError()
+	}
+	return int(num), nil
+}
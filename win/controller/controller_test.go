@@ -0,0 +1,105 @@
+package controller
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/mewmew/sdl/win"
+)
+
+// polled holds the controller events returned by pollEvent.
+var polled []interface{}
+
+// pollEvent polls window events until an event of the same type as want is
+// returned, and reports an error if it differs from want.
+func pollEvent(t *testing.T, want interface{}) {
+	t.Helper()
+	for i := 0; i < 1000; i++ {
+		event := win.PollEvent()
+		if _, ok := encodeEvent(event); ok {
+			polled = append(polled, event)
+		}
+		if reflect.TypeOf(event) != reflect.TypeOf(want) {
+			continue
+		}
+		if !reflect.DeepEqual(event, want) {
+			t.Errorf("event mismatch; expected %#v, got %#v", want, event)
+		}
+		return
+	}
+	t.Errorf("missing event; expected %#v", want)
+}
+
+func TestVirtual(t *testing.T) {
+	// The headless window never has input focus, so controller input must be
+	// delivered as background events.
+	setHint("SDL_JOYSTICK_ALLOW_BACKGROUND_EVENTS", "1")
+	// Closing a window must not quit the game controller subsystem.
+	err := win.Open(64, 48, win.Options{Headless: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	win.Close()
+	err = win.Open(64, 48, win.Options{Headless: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer win.Close()
+	polled = nil
+	buf := new(bytes.Buffer)
+	err = win.StartRecording(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Attach, drive and detach a virtual controller.
+	index, err := AttachVirtual()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsVirtual(index) {
+		t.Errorf("device %d; expected virtual device", index)
+	}
+	pollEvent(t, Added{Index: index})
+	ctrl, err := Open(index)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ctrl.Close()
+	id := ctrl.ID()
+	if err := ctrl.SetVirtualButton(ButtonA, true); err != nil {
+		t.Fatal(err)
+	}
+	pollEvent(t, ButtonPress{ID: id, Button: ButtonA})
+	if !ctrl.IsDown(ButtonA) {
+		t.Error("button A not held down")
+	}
+	if err := ctrl.SetVirtualAxis(AxisLeftX, -12345); err != nil {
+		t.Fatal(err)
+	}
+	pollEvent(t, AxisMotion{ID: id, Axis: AxisLeftX, Value: -12345})
+	if err := DetachVirtual(index); err != nil {
+		t.Fatal(err)
+	}
+	pollEvent(t, Removed{ID: id})
+
+	// The controller events must be recorded.
+	err = win.StopRecording()
+	if err != nil {
+		t.Fatal(err)
+	}
+	events, err := win.ReadEvents(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []interface{}
+	for _, event := range events {
+		if _, ok := encodeEvent(event.Event); ok {
+			got = append(got, event.Event)
+		}
+	}
+	if !reflect.DeepEqual(got, polled) {
+		t.Errorf("recorded events mismatch; expected %#v, got %#v", polled, got)
+	}
+}
@@ -0,0 +1,192 @@
+package controller
+
+// #include <SDL2/SDL.h>
+//
+// static int getEventType(SDL_Event *e) {
+//    return e->type;
+// }
+//
+// static SDL_ControllerDeviceEvent * getDeviceEvent(SDL_Event *e) {
+//    return &e->cdevice;
+// }
+//
+// static SDL_ControllerButtonEvent * getButtonEvent(SDL_Event *e) {
+//    return &e->cbutton;
+// }
+//
+// static SDL_ControllerAxisEvent * getAxisEvent(SDL_Event *e) {
+//    return &e->caxis;
+// }
+import "C"
+
+import (
+	"fmt"
+	"unsafe"
+)
+
+// Added is a controller event which is triggered when a game controller is
+// connected. It is also triggered at startup for controllers which are already
+// connected.
+type Added struct {
+	// The device index of the controller, which is passed to Open.
+	Index int
+}
+
+// Removed is a controller event which is triggered when an opened game
+// controller is disconnected.
+type Removed struct {
+	// The ID of the controller.
+	ID ID
+}
+
+// Remapped is a controller event which is triggered when the mapping of an
+// opened game controller has changed.
+type Remapped struct {
+	// The ID of the controller.
+	ID ID
+}
+
+// ButtonPress is a controller event which is triggered when a button is
+// pressed.
+type ButtonPress struct {
+	// The ID of the controller.
+	ID ID
+	// The pressed button.
+	Button Button
+}
+
+// ButtonRelease is a controller event which is triggered when a button is
+// released.
+type ButtonRelease struct {
+	// The ID of the controller.
+	ID ID
+	// The released button.
+	Button Button
+}
+
+// AxisMotion is a controller event which is triggered when the value of an
+// axis changes.
+type AxisMotion struct {
+	// The ID of the controller.
+	ID ID
+	// The moved axis.
+	Axis Axis
+	// The new value of the axis; see Controller.Axis.
+	Value int16
+}
+
+// goEvent returns the corresponding Go event for the provided SDL_Event or nil
+// if no such Go event exists. It is registered as an event translator of
+// win.PollEvent.
+func goEvent(p unsafe.Pointer) (event interface{}) {
+	cEvent := (*C.SDL_Event)(p)
+	switch C.getEventType(cEvent) {
+	// Hot-plug events.
+	case C.SDL_CONTROLLERDEVICEADDED:
+		e := C.getDeviceEvent(cEvent)
+		return Added{Index: int(e.which)}
+	case C.SDL_CONTROLLERDEVICEREMOVED:
+		e := C.getDeviceEvent(cEvent)
+		return Removed{ID: ID(e.which)}
+	case C.SDL_CONTROLLERDEVICEREMAPPED:
+		e := C.getDeviceEvent(cEvent)
+		return Remapped{ID: ID(e.which)}
+
+	// Button events.
+	case C.SDL_CONTROLLERBUTTONDOWN:
+		e := C.getButtonEvent(cEvent)
+		event = ButtonPress{
+			ID:     ID(e.which),
+			Button: Button(e.button),
+		}
+		return event
+	case C.SDL_CONTROLLERBUTTONUP:
+		e := C.getButtonEvent(cEvent)
+		event = ButtonRelease{
+			ID:     ID(e.which),
+			Button: Button(e.button),
+		}
+		return event
+
+	// Axis events.
+	case C.SDL_CONTROLLERAXISMOTION:
+		e := C.getAxisEvent(cEvent)
+		event = AxisMotion{
+			ID:    ID(e.which),
+			Axis:  Axis(e.axis),
+			Value: int16(e.value),
+		}
+		return event
+	}
+
+	// Ignore event.
+	return nil
+}
+
+// Event kinds of controller events in event recordings.
+const (
+	kindAdded int64 = iota
+	kindRemoved
+	kindRemapped
+	kindButtonPress
+	kindButtonRelease
+	kindAxisMotion
+)
+
+// encodeEvent returns the event recording fields of the provided controller
+// event. It is registered as an event codec of win.StartRecording.
+func encodeEvent(event interface{}) (fields []int64, ok bool) {
+	switch e := event.(type) {
+	case Added:
+		return []int64{kindAdded, int64(e.Index)}, true
+	case Removed:
+		return []int64{kindRemoved, int64(e.ID)}, true
+	case Remapped:
+		return []int64{kindRemapped, int64(e.ID)}, true
+	case ButtonPress:
+		return []int64{kindButtonPress, int64(e.ID), int64(e.Button)}, true
+	case ButtonRelease:
+		return []int64{kindButtonRelease, int64(e.ID), int64(e.Button)}, true
+	case AxisMotion:
+		return []int64{kindAxisMotion, int64(e.ID), int64(e.Axis), int64(e.Value)}, true
+	}
+	return nil, false
+}
+
+// decodeEvent returns the controller event of the provided event recording
+// fields. It is registered as an event codec of win.ReadEvents.
+func decodeEvent(fields []int64) (event interface{}, err error) {
+	// want reports whether the event has the expected number of fields.
+	want := func(n int) bool {
+		return len(fields) == n
+	}
+	if len(fields) > 0 {
+		switch fields[0] {
+		case kindAdded:
+			if want(2) {
+				return Added{Index: int(fields[1])}, nil
+			}
+		case kindRemoved:
+			if want(2) {
+				return Removed{ID: ID(fields[1])}, nil
+			}
+		case kindRemapped:
+			if want(2) {
+				return Remapped{ID: ID(fields[1])}, nil
+			}
+		case kindButtonPress:
+			if want(3) {
+				return ButtonPress{ID: ID(fields[1]), Button: Button(fields[2])}, nil
+			}
+		case kindButtonRelease:
+			if want(3) {
+				return ButtonRelease{ID: ID(fields[1]), Button: Button(fields[2])}, nil
+			}
+		case kindAxisMotion:
+			if want(4) {
+				return AxisMotion{ID: ID(fields[1]), Axis: Axis(fields[2]), Value: int16(fields[3])}, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("controller.decodeEvent: invalid controller event fields %v", fields)
+}
@@ -0,0 +1,64 @@
+package controller
+
+// #include <SDL2/SDL.h>
+import "C"
+
+// AttachVirtual attaches a virtual game controller and returns its device
+// index, which may be passed to Open. The buttons and axes of an opened virtual
+// controller are driven through SetVirtualButton and SetVirtualAxis, which
+// makes it possible to test controller input without a physical device, e.g.
+// in headless CI environments. As with physical controllers, SDL only reports
+// their input while the window has input focus, unless background events are
+// enabled through the SDL_JOYSTICK_ALLOW_BACKGROUND_EVENTS environment variable
+// prior to initialization.
+//
+// Note: The DetachVirtual function should be called when finished using the
+// virtual controller.
+func AttachVirtual() (index int, err error) {
+	i := C.SDL_JoystickAttachVirtual(C.SDL_JOYSTICK_TYPE_GAMECONTROLLER, numAxes, numButtons, 0)
+	if i < 0 {
+		return 0, getError()
+	}
+	return int(i), nil
+}
+
+// DetachVirtual detaches the virtual game controller of the provided device
+// index.
+func DetachVirtual(index int) (err error) {
+	if C.SDL_JoystickDetachVirtual(C.int(index)) != 0 {
+		return getError()
+	}
+	return nil
+}
+
+// IsVirtual returns true if the joystick of the provided device index is a
+// virtual device, and false otherwise.
+func IsVirtual(index int) bool {
+	return C.SDL_JoystickIsVirtual(C.int(index)) == C.SDL_TRUE
+}
+
+// SetVirtualButton sets the state of the provided button of a virtual
+// controller. The change is reported by controller events once processed by
+// win.PollEvent.
+func (ctrl *Controller) SetVirtualButton(button Button, pressed bool) (err error) {
+	var value C.Uint8
+	if pressed {
+		value = 1
+	}
+	joystick := C.SDL_GameControllerGetJoystick(ctrl.c)
+	if C.SDL_JoystickSetVirtualButton(joystick, C.int(button), value) != 0 {
+		return getError()
+	}
+	return nil
+}
+
+// SetVirtualAxis sets the value of the provided axis of a virtual controller.
+// The change is reported by controller events once processed by
+// win.PollEvent.
+func (ctrl *Controller) SetVirtualAxis(axis Axis, value int16) (err error) {
+	joystick := C.SDL_GameControllerGetJoystick(ctrl.c)
+	if C.SDL_JoystickSetVirtualAxis(joystick, C.int(axis), C.Sint16(value)) != 0 {
+		return getError()
+	}
+	return nil
+}
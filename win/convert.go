@@ -0,0 +1,28 @@
+package win
+
+// #cgo pkg-config: sdl2
+// #include <SDL2/SDL.h>
+import "C"
+
+// This file contains helpers which convert images to other pixel formats than
+// the standard image format, e.g. to test drawing onto the pixel formats of
+// windows.
+
+// convertMasks returns a copy of the image converted to the packed pixel format
+// with the provided bits per pixel and channel masks.
+func (img *Image) convertMasks(depth int, rmask, gmask, bmask, amask uint32) (conv *Image, err error) {
+	format := C.SDL_MasksToPixelFormatEnum(C.int(depth), C.Uint32(rmask), C.Uint32(gmask), C.Uint32(bmask), C.Uint32(amask))
+	if format == C.SDL_PIXELFORMAT_UNKNOWN {
+		return nil, getError()
+	}
+	s := C.SDL_ConvertSurfaceFormat(img.s, C.Uint32(format), 0)
+	if s == nil {
+		return nil, getError()
+	}
+	conv = &Image{
+		Width:  int(s.w),
+		Height: int(s.h),
+		s:      s,
+	}
+	return conv, nil
+}
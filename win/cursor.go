@@ -0,0 +1,107 @@
+package win
+
+// #cgo pkg-config: sdl2
+// #include <SDL2/SDL.h>
+import "C"
+
+import (
+	"image"
+)
+
+// A Cursor is a mouse cursor.
+type Cursor struct {
+	// C cursor pointer.
+	c *C.SDL_Cursor
+}
+
+// NewCursor returns a new color cursor of the provided image. The hotspot is
+// the point of the image which is aligned with the position of the mouse.
+//
+// Note: The Free method of the cursor should be called when finished using it.
+func NewCursor(img *Image, hotspot image.Point) (cursor *Cursor, err error) {
+	cursor = new(Cursor)
+	cursor.c = C.SDL_CreateColorCursor(img.s, C.int(hotspot.X), C.int(hotspot.Y))
+	if cursor.c == nil {
+		return nil, getError()
+	}
+	return cursor, nil
+}
+
+// SystemCursor specifies a cursor provided by the operating system.
+type SystemCursor int
+
+// System cursors.
+const (
+	// CursorArrow is the default arrow cursor.
+	CursorArrow SystemCursor = C.SDL_SYSTEM_CURSOR_ARROW
+	// CursorIBeam is the I-beam cursor, used for text selection.
+	CursorIBeam SystemCursor = C.SDL_SYSTEM_CURSOR_IBEAM
+	// CursorWait is the wait cursor.
+	CursorWait SystemCursor = C.SDL_SYSTEM_CURSOR_WAIT
+	// CursorCrosshair is the crosshair cursor.
+	CursorCrosshair SystemCursor = C.SDL_SYSTEM_CURSOR_CROSSHAIR
+	// CursorWaitArrow is the small wait cursor, or the wait cursor if
+	// unavailable.
+	CursorWaitArrow SystemCursor = C.SDL_SYSTEM_CURSOR_WAITARROW
+	// CursorResizeNWSE is the double arrow cursor pointing northwest and
+	// southeast.
+	CursorResizeNWSE SystemCursor = C.SDL_SYSTEM_CURSOR_SIZENWSE
+	// CursorResizeNESW is the double arrow cursor pointing northeast and
+	// southwest.
+	CursorResizeNESW SystemCursor = C.SDL_SYSTEM_CURSOR_SIZENESW
+	// CursorResizeWE is the double arrow cursor pointing west and east.
+	CursorResizeWE SystemCursor = C.SDL_SYSTEM_CURSOR_SIZEWE
+	// CursorResizeNS is the double arrow cursor pointing north and south.
+	CursorResizeNS SystemCursor = C.SDL_SYSTEM_CURSOR_SIZENS
+	// CursorResizeAll is the four pointed arrow cursor pointing north, south,
+	// east and west.
+	CursorResizeAll SystemCursor = C.SDL_SYSTEM_CURSOR_SIZEALL
+	// CursorNo is the slashed circle or crossbones cursor.
+	CursorNo SystemCursor = C.SDL_SYSTEM_CURSOR_NO
+	// CursorHand is the hand cursor, used for links.
+	CursorHand SystemCursor = C.SDL_SYSTEM_CURSOR_HAND
+)
+
+// NewSystemCursor returns a new cursor of the provided system cursor.
+//
+// Note: The Free method of the cursor should be called when finished using it.
+func NewSystemCursor(id SystemCursor) (cursor *Cursor, err error) {
+	cursor = new(Cursor)
+	cursor.c = C.SDL_CreateSystemCursor(C.SDL_SystemCursor(id))
+	if cursor.c == nil {
+		return nil, getError()
+	}
+	return cursor, nil
+}
+
+// Free frees the cursor.
+//
+// Note: The cursor must not be active when freed. Call SetCursor(nil) first to
+// restore the default cursor.
+func (cursor *Cursor) Free() {
+	C.SDL_FreeCursor(cursor.c)
+}
+
+// SetCursor sets the active cursor. The default cursor is restored if cursor
+// is nil.
+func SetCursor(cursor *Cursor) {
+	if cursor == nil {
+		C.SDL_SetCursor(C.SDL_GetDefaultCursor())
+		return
+	}
+	C.SDL_SetCursor(cursor.c)
+}
+
+// ShowCursor shows or hides the cursor.
+func ShowCursor(show bool) {
+	toggle := C.int(C.SDL_DISABLE)
+	if show {
+		toggle = C.SDL_ENABLE
+	}
+	C.SDL_ShowCursor(toggle)
+}
+
+// CursorVisible returns true if the cursor is shown, and false otherwise.
+func CursorVisible() bool {
+	return C.SDL_ShowCursor(C.SDL_QUERY) == C.SDL_ENABLE
+}
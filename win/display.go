@@ -0,0 +1,161 @@
+package win
+
+// #cgo pkg-config: sdl2
+// #include <SDL2/SDL.h>
+import "C"
+
+import (
+	"image"
+)
+
+// A Display is a monitor connected to the system.
+type Display struct {
+	// The index of the display.
+	Index int
+	// The name of the display.
+	Name string
+	// The bounds of the display in desktop coordinates.
+	Bounds image.Rectangle
+	// The usable bounds of the display in desktop coordinates, which exclude
+	// areas reserved by the system such as task bars and menu bars.
+	UsableBounds image.Rectangle
+	// The diagonal, horizontal and vertical DPI of the display, or 0 if
+	// unknown.
+	DDPI, HDPI, VDPI float64
+	// The current video mode of the display.
+	CurrentMode DisplayMode
+	// The video mode of the desktop; which differs from the current video mode
+	// while a window is in exclusive full screen mode.
+	DesktopMode DisplayMode
+}
+
+// A DisplayMode is a video mode supported by a display.
+type DisplayMode struct {
+	// The width and height of the video mode.
+	Width, Height int
+	// The refresh rate in Hz, or 0 if unknown.
+	RefreshRate int
+	// SDL pixel format of the video mode.
+	format C.Uint32
+}
+
+// Displays returns the displays connected to the system.
+func Displays() (displays []Display, err error) {
+	err = initVideo()
+	if err != nil {
+		return nil, err
+	}
+	n := C.SDL_GetNumVideoDisplays()
+	if n < 0 {
+		return nil, getError()
+	}
+	for i := C.int(0); i < n; i++ {
+		d := Display{
+			Index: int(i),
+			Name:  C.GoString(C.SDL_GetDisplayName(i)),
+		}
+		var rect C.SDL_Rect
+		if C.SDL_GetDisplayBounds(i, &rect) != 0 {
+			return nil, getError()
+		}
+		d.Bounds = goRect(rect)
+		if C.SDL_GetDisplayUsableBounds(i, &rect) != 0 {
+			return nil, getError()
+		}
+		d.UsableBounds = goRect(rect)
+		// The DPI is unknown on some platforms; in which case it is left as 0.
+		var ddpi, hdpi, vdpi C.float
+		if C.SDL_GetDisplayDPI(i, &ddpi, &hdpi, &vdpi) == 0 {
+			d.DDPI, d.HDPI, d.VDPI = float64(ddpi), float64(hdpi), float64(vdpi)
+		}
+		var mode C.SDL_DisplayMode
+		if C.SDL_GetCurrentDisplayMode(i, &mode) != 0 {
+			return nil, getError()
+		}
+		d.CurrentMode = goDisplayMode(mode)
+		if C.SDL_GetDesktopDisplayMode(i, &mode) != 0 {
+			return nil, getError()
+		}
+		d.DesktopMode = goDisplayMode(mode)
+		displays = append(displays, d)
+	}
+	return displays, nil
+}
+
+// DisplayModes returns the video modes supported by the display of the
+// provided index. The video modes are sorted from largest to smallest
+// resolution, and from highest to lowest refresh rate.
+func DisplayModes(index int) (modes []DisplayMode, err error) {
+	err = initVideo()
+	if err != nil {
+		return nil, err
+	}
+	n := C.SDL_GetNumDisplayModes(C.int(index))
+	if n < 0 {
+		return nil, getError()
+	}
+	for i := C.int(0); i < n; i++ {
+		var mode C.SDL_DisplayMode
+		if C.SDL_GetDisplayMode(C.int(index), i, &mode) != 0 {
+			return nil, getError()
+		}
+		modes = append(modes, goDisplayMode(mode))
+	}
+	return modes, nil
+}
+
+// Center returns the position of the top left corner of a window with the
+// provided dimensions, centered within the bounds of the display.
+func (d Display) Center(width, height int) (pt image.Point) {
+	x := d.Bounds.Min.X + (d.Bounds.Dx()-width)/2
+	y := d.Bounds.Min.Y + (d.Bounds.Dy()-height)/2
+	return image.Pt(x, y)
+}
+
+// SetDisplayMode sets the video mode of the display while the window is in
+// exclusive full screen mode.
+func SetDisplayMode(mode DisplayMode) (err error) {
+	cMode := C.SDL_DisplayMode{
+		format:       mode.format,
+		w:            C.int(mode.Width),
+		h:            C.int(mode.Height),
+		refresh_rate: C.int(mode.RefreshRate),
+	}
+	if C.SDL_SetWindowDisplayMode(w, &cMode) != 0 {
+		return getError()
+	}
+	return nil
+}
+
+// WindowDisplay returns the index of the display which contains the center of
+// the window.
+func WindowDisplay() (index int, err error) {
+	i := C.SDL_GetWindowDisplayIndex(w)
+	if i < 0 {
+		return 0, getError()
+	}
+	return int(i), nil
+}
+
+// goDisplayMode returns the corresponding DisplayMode for the provided
+// SDL_DisplayMode.
+func goDisplayMode(mode C.SDL_DisplayMode) DisplayMode {
+	return DisplayMode{
+		Width:       int(mode.w),
+		Height:      int(mode.h),
+		RefreshRate: int(mode.refresh_rate),
+		format:      mode.format,
+	}
+}
+
+// initVideo initializes the SDL video subsystem, unless already initialized.
+// It allows displays to be queried before the window is opened.
+func initVideo() (err error) {
+	if C.SDL_WasInit(C.SDL_INIT_VIDEO) != 0 {
+		return nil
+	}
+	if C.SDL_InitSubSystem(C.SDL_INIT_VIDEO) != 0 {
+		return getError()
+	}
+	return nil
+}
@@ -0,0 +1,333 @@
+package win
+
+// #cgo pkg-config: sdl2
+// #include <SDL2/SDL.h>
+import "C"
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"math"
+	"unsafe"
+
+	"golang.org/x/image/vector"
+)
+
+// Fill fills the entire image with the provided color. The pixels are
+// replaced, not blended.
+func (img *Image) Fill(c color.Color) (err error) {
+	if C.SDL_FillRect(img.s, nil, img.mapColor(c)) != 0 {
+		return getError()
+	}
+	return nil
+}
+
+// FillRect fills the rectangle r of the image with the provided color. The
+// pixels are replaced, not blended. Nothing is filled if r is empty; use Fill
+// to fill the entire image.
+func (img *Image) FillRect(r image.Rectangle, c color.Color) (err error) {
+	if r.Empty() {
+		return nil
+	}
+	if C.SDL_FillRect(img.s, cRect(r), img.mapColor(c)) != 0 {
+		return getError()
+	}
+	return nil
+}
+
+// FillRects fills the provided rectangles of the image with the provided
+// color. The pixels are replaced, not blended. Empty rectangles are skipped.
+func (img *Image) FillRects(rects []image.Rectangle, c color.Color) (err error) {
+	cRects := make([]C.SDL_Rect, 0, len(rects))
+	for _, r := range rects {
+		if r.Empty() {
+			continue
+		}
+		cRects = append(cRects, C.SDL_Rect{x: C.int(r.Min.X), y: C.int(r.Min.Y), w: C.int(r.Dx()), h: C.int(r.Dy())})
+	}
+	if len(cRects) == 0 {
+		return nil
+	}
+	if C.SDL_FillRects(img.s, &cRects[0], C.int(len(cRects)), img.mapColor(c)) != 0 {
+		return getError()
+	}
+	return nil
+}
+
+// mapColor maps the provided color to a pixel value of the image.
+func (img *Image) mapColor(c color.Color) C.Uint32 {
+	nc := color.NRGBAModel.Convert(c).(color.NRGBA)
+	return C.SDL_MapRGBA(img.s.format, C.Uint8(nc.R), C.Uint8(nc.G), C.Uint8(nc.B), C.Uint8(nc.A))
+}
+
+// The following primitives are anti-aliased and alpha blended onto the pixels
+// of the image. Points refer to the centers of pixels, so that a horizontal
+// line of width 1 between two points covers exactly the pixels from the first
+// to the last point.
+
+// Line draws a line of the provided width and color between the points p0 and
+// p1. The ends of the line are square.
+func (img *Image) Line(p0, p1 image.Point, width int, c color.Color) (err error) {
+	return img.Polyline([]image.Point{p0, p1}, width, c)
+}
+
+// Polyline draws connected lines of the provided width and color between the
+// consecutive points of pts. The lines are joined by rounded corners, and the
+// ends of the polyline are square.
+func (img *Image) Polyline(pts []image.Point, width int, c color.Color) (err error) {
+	if len(pts) == 0 {
+		return nil
+	}
+	return img.fillPolys(stroke(centers(pts), false, float64(width)), c)
+}
+
+// Polygon draws the outline of the polygon with the provided vertices, using
+// lines of the provided width and color.
+func (img *Image) Polygon(pts []image.Point, width int, c color.Color) (err error) {
+	if len(pts) == 0 {
+		return nil
+	}
+	return img.fillPolys(stroke(centers(pts), true, float64(width)), c)
+}
+
+// FillPolygon fills the polygon with the provided vertices using the provided
+// color. The polygon may be concave and self-intersecting; its interior is
+// determined by the non-zero winding rule.
+func (img *Image) FillPolygon(pts []image.Point, c color.Color) (err error) {
+	if len(pts) < 3 {
+		return nil
+	}
+	return img.fillPolys([][]point{centers(pts)}, c)
+}
+
+// Circle draws the outline of the circle with the provided center and radius,
+// using a line of the provided width and color.
+func (img *Image) Circle(center image.Point, radius, width int, c color.Color) (err error) {
+	return img.Ellipse(center, radius, radius, width, c)
+}
+
+// FillCircle fills the circle with the provided center and radius using the
+// provided color.
+func (img *Image) FillCircle(center image.Point, radius int, c color.Color) (err error) {
+	return img.FillEllipse(center, radius, radius, c)
+}
+
+// Ellipse draws the outline of the axis-aligned ellipse with the provided
+// center and horizontal and vertical radii, using a line of the provided width
+// and color.
+func (img *Image) Ellipse(center image.Point, rx, ry, width int, c color.Color) (err error) {
+	p := centers([]image.Point{center})[0]
+	w := float64(width) / 2
+	polys := [][]point{ellipse(p, float64(rx)+w, float64(ry)+w, false)}
+	if inX, inY := float64(rx)-w, float64(ry)-w; inX > 0 && inY > 0 {
+		polys = append(polys, ellipse(p, inX, inY, true))
+	}
+	return img.fillPolys(polys, c)
+}
+
+// FillEllipse fills the axis-aligned ellipse with the provided center and
+// horizontal and vertical radii using the provided color.
+func (img *Image) FillEllipse(center image.Point, rx, ry int, c color.Color) (err error) {
+	p := centers([]image.Point{center})[0]
+	// Extend the ellipse by half a pixel, to cover the pixels at the radius
+	// like Ellipse does.
+	return img.fillPolys([][]point{ellipse(p, float64(rx)+0.5, float64(ry)+0.5, false)}, c)
+}
+
+// RoundedRect draws the outline of the rectangle r with corners rounded by the
+// provided radius, using a line of the provided width and color. The outline
+// lies within r.
+func (img *Image) RoundedRect(r image.Rectangle, radius, width int, c color.Color) (err error) {
+	r = r.Canon()
+	outer := rect(r)
+	polys := [][]point{roundedRect(outer, float64(radius), false)}
+	w := float64(width)
+	inner := frect{outer.min.add(point{w, w}), outer.max.sub(point{w, w})}
+	if inner.max.x > inner.min.x && inner.max.y > inner.min.y {
+		polys = append(polys, roundedRect(inner, math.Max(float64(radius)-w, 0), true))
+	}
+	return img.fillPolys(polys, c)
+}
+
+// FillRoundedRect fills the rectangle r with corners rounded by the provided
+// radius using the provided color.
+func (img *Image) FillRoundedRect(r image.Rectangle, radius int, c color.Color) (err error) {
+	return img.fillPolys([][]point{roundedRect(rect(r.Canon()), float64(radius), false)}, c)
+}
+
+// fillPolys fills the union of the provided closed polygons using the provided
+// color, determined by the non-zero winding rule. The coverage of each pixel is
+// anti-aliased and alpha blended onto the image.
+func (img *Image) fillPolys(polys [][]point, c color.Color) (err error) {
+	// Determine the pixels affected by the polygons.
+	min := point{math.Inf(1), math.Inf(1)}
+	max := point{math.Inf(-1), math.Inf(-1)}
+	for _, poly := range polys {
+		for _, p := range poly {
+			min = point{math.Min(min.x, p.x), math.Min(min.y, p.y)}
+			max = point{math.Max(max.x, p.x), math.Max(max.y, p.y)}
+		}
+	}
+	if min.x > max.x || min.y > max.y {
+		return nil
+	}
+	bounds := image.Rect(int(math.Floor(min.x)), int(math.Floor(min.y)), int(math.Ceil(max.x)), int(math.Ceil(max.y)))
+	bounds = bounds.Intersect(goRect(img.s.clip_rect))
+	if bounds.Empty() {
+		return nil
+	}
+
+	// Rasterize the coverage of the polygons.
+	z := vector.NewRasterizer(bounds.Dx(), bounds.Dy())
+	dx, dy := float64(bounds.Min.X), float64(bounds.Min.Y)
+	for _, poly := range polys {
+		if len(poly) < 3 {
+			continue
+		}
+		z.MoveTo(float32(poly[0].x-dx), float32(poly[0].y-dy))
+		for _, p := range poly[1:] {
+			z.LineTo(float32(p.x-dx), float32(p.y-dy))
+		}
+		z.ClosePath()
+	}
+	mask := image.NewAlpha(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	z.Draw(mask, mask.Bounds(), image.Opaque, image.ZP)
+
+	// Blend the color onto the pixels of the image.
+	pf, err := newPixelFormat(img.s.format)
+	if err != nil {
+		return err
+	}
+	if C.SDL_LockSurface(img.s) != 0 {
+		return getError()
+	}
+	defer C.SDL_UnlockSurface(img.s)
+	src := color.NRGBAModel.Convert(c).(color.NRGBA)
+	pitch := int(img.s.pitch)
+	pix := surfacePix(img.s.pixels, pitch*int(img.s.h))
+	for y := 0; y < bounds.Dy(); y++ {
+		line := pix[(bounds.Min.Y+y)*pitch:]
+		for x := 0; x < bounds.Dx(); x++ {
+			cov := mask.Pix[y*mask.Stride+x]
+			if cov == 0 {
+				continue
+			}
+			i := (bounds.Min.X + x) * pf.bpp
+			pf.set(line[i:], blend(pf.get(line[i:]), src, uint32(cov), pf.hasAlpha()))
+		}
+	}
+	return nil
+}
+
+// blend blends the source color with the provided coverage, in the range from
+// 0 to 255, onto the destination color.
+func blend(dst, src color.NRGBA, cov uint32, dstAlpha bool) color.NRGBA {
+	sa := uint32(src.A) * cov / 0xFF
+	if !dstAlpha {
+		//    dstRGB = srcRGB*srcA + dstRGB*(1-srcA)
+		mix := func(s, d uint8) uint8 {
+			return uint8((uint32(s)*sa + uint32(d)*(0xFF-sa)) / 0xFF)
+		}
+		return color.NRGBA{R: mix(src.R, dst.R), G: mix(src.G, dst.G), B: mix(src.B, dst.B), A: 0xFF}
+	}
+	//    dstA = srcA + dstA*(1-srcA)
+	//    dstRGB = (srcRGB*srcA + dstRGB*dstA*(1-srcA)) / dstA'
+	da := uint32(dst.A)
+	a := sa*0xFF + da*(0xFF-sa)
+	if a == 0 {
+		return color.NRGBA{}
+	}
+	mix := func(s, d uint8) uint8 {
+		return uint8((uint32(s)*sa*0xFF + uint32(d)*da*(0xFF-sa)) / a)
+	}
+	return color.NRGBA{R: mix(src.R, dst.R), G: mix(src.G, dst.G), B: mix(src.B, dst.B), A: uint8(a / 0xFF)}
+}
+
+// A pixelFormat describes the memory layout of packed pixels with 2, 3 or 4
+// bytes per pixel.
+type pixelFormat struct {
+	// The number of bytes per pixel.
+	bpp int
+	// Red, green, blue and alpha masks and shifts.
+	masks, shifts [4]uint32
+}
+
+// newPixelFormat returns the pixel format described by the provided SDL pixel
+// format.
+func newPixelFormat(format *C.SDL_PixelFormat) (pf *pixelFormat, err error) {
+	pf = &pixelFormat{
+		bpp:    int(format.BytesPerPixel),
+		masks:  [4]uint32{uint32(format.Rmask), uint32(format.Gmask), uint32(format.Bmask), uint32(format.Amask)},
+		shifts: [4]uint32{uint32(format.Rshift), uint32(format.Gshift), uint32(format.Bshift), uint32(format.Ashift)},
+	}
+	if pf.bpp < 2 || pf.bpp > 4 || format.palette != nil {
+		return nil, errors.New("win.newPixelFormat: unsupported pixel format; only packed pixel formats of 2, 3 or 4 bytes per pixel are supported")
+	}
+	return pf, nil
+}
+
+// hasAlpha reports whether the pixel format has an alpha channel.
+func (pf *pixelFormat) hasAlpha() bool {
+	return pf.masks[3] != 0
+}
+
+// get returns the color of the pixel stored at the start of buf.
+func (pf *pixelFormat) get(buf []byte) color.NRGBA {
+	v := pf.load(buf)
+	var ch [4]uint8
+	for i, mask := range pf.masks {
+		if mask == 0 {
+			ch[i] = 0xFF
+			continue
+		}
+		max := mask >> pf.shifts[i]
+		ch[i] = uint8((v & mask) >> pf.shifts[i] * 0xFF / max)
+	}
+	return color.NRGBA{R: ch[0], G: ch[1], B: ch[2], A: ch[3]}
+}
+
+// set stores the color as a pixel at the start of buf.
+func (pf *pixelFormat) set(buf []byte, c color.NRGBA) {
+	var v uint32
+	for i, ch := range [4]uint8{c.R, c.G, c.B, c.A} {
+		mask := pf.masks[i]
+		if mask == 0 {
+			continue
+		}
+		max := mask >> pf.shifts[i]
+		v |= (uint32(ch)*max + 0x7F) / 0xFF << pf.shifts[i]
+	}
+	pf.store(buf, v)
+}
+
+// load loads the pixel value stored at the start of buf, in native byte order.
+func (pf *pixelFormat) load(buf []byte) uint32 {
+	switch pf.bpp {
+	case 2:
+		return uint32(*(*uint16)(unsafe.Pointer(&buf[0])))
+	case 3:
+		if nativeBigEndian {
+			return uint32(buf[0])<<16 | uint32(buf[1])<<8 | uint32(buf[2])
+		}
+		return uint32(buf[0]) | uint32(buf[1])<<8 | uint32(buf[2])<<16
+	default:
+		return *(*uint32)(unsafe.Pointer(&buf[0]))
+	}
+}
+
+// store stores the pixel value at the start of buf, in native byte order.
+func (pf *pixelFormat) store(buf []byte, v uint32) {
+	switch pf.bpp {
+	case 2:
+		*(*uint16)(unsafe.Pointer(&buf[0])) = uint16(v)
+	case 3:
+		if nativeBigEndian {
+			buf[0], buf[1], buf[2] = uint8(v>>16), uint8(v>>8), uint8(v)
+		} else {
+			buf[0], buf[1], buf[2] = uint8(v), uint8(v>>8), uint8(v>>16)
+		}
+	default:
+		*(*uint32)(unsafe.Pointer(&buf[0])) = v
+	}
+}
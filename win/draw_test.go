@@ -0,0 +1,308 @@
+package win
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestFillRect(t *testing.T) {
+	blue := color.NRGBA{B: 0xFF, A: 0xFF}
+	red := color.NRGBA{R: 0xFF, A: 0xFF}
+	img := newTestImage(t, image.NewNRGBA(image.Rect(0, 0, 4, 3)))
+	if err := img.Fill(blue); err != nil {
+		t.Fatal(err)
+	}
+	// Empty rectangles fill nothing.
+	for _, r := range []image.Rectangle{image.ZR, image.Rect(1, 1, 1, 3), image.Rect(2, 2, 4, 2)} {
+		if err := img.FillRect(r, red); err != nil {
+			t.Fatal(err)
+		}
+	}
+	fill := image.Rect(1, 1, 3, 2)
+	if err := img.FillRect(fill, red); err != nil {
+		t.Fatal(err)
+	}
+	got := toNRGBA(t, img)
+	for y := 0; y < 3; y++ {
+		for x := 0; x < 4; x++ {
+			want := blue
+			if image.Pt(x, y).In(fill) {
+				want = red
+			}
+			if c := got.NRGBAAt(x, y); c != want {
+				t.Errorf("pixel (%d, %d) mismatch; expected %v, got %v", x, y, want, c)
+			}
+		}
+	}
+}
+
+func TestFillRects(t *testing.T) {
+	blue := color.NRGBA{B: 0xFF, A: 0xFF}
+	red := color.NRGBA{R: 0xFF, A: 0xFF}
+	img := newTestImage(t, image.NewNRGBA(image.Rect(0, 0, 4, 3)))
+	if err := img.Fill(blue); err != nil {
+		t.Fatal(err)
+	}
+	// Empty rectangles fill nothing, also when mixed with non-empty ones.
+	if err := img.FillRects([]image.Rectangle{image.ZR, image.Rect(2, 2, 4, 2)}, red); err != nil {
+		t.Fatal(err)
+	}
+	fill := []image.Rectangle{image.Rect(0, 0, 1, 1), image.ZR, image.Rect(2, 1, 4, 2)}
+	if err := img.FillRects(fill, red); err != nil {
+		t.Fatal(err)
+	}
+	got := toNRGBA(t, img)
+	for y := 0; y < 3; y++ {
+		for x := 0; x < 4; x++ {
+			want := blue
+			if image.Pt(x, y).In(fill[0]) || image.Pt(x, y).In(fill[2]) {
+				want = red
+			}
+			if c := got.NRGBAAt(x, y); c != want {
+				t.Errorf("pixel (%d, %d) mismatch; expected %v, got %v", x, y, want, c)
+			}
+		}
+	}
+}
+
+// drawTest returns a test image of the specified dimensions filled with the
+// provided color.
+func drawTest(t *testing.T, width, height int, c color.NRGBA) *Image {
+	t.Helper()
+	img := newTestImage(t, image.NewNRGBA(image.Rect(0, 0, width, height)))
+	if err := img.Fill(c); err != nil {
+		t.Fatal(err)
+	}
+	return img
+}
+
+func TestLine(t *testing.T) {
+	black := color.NRGBA{A: 0xFF}
+	white := color.NRGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF}
+	img := drawTest(t, 8, 8, black)
+	if err := img.Line(image.Pt(1, 3), image.Pt(6, 3), 1, white); err != nil {
+		t.Fatal(err)
+	}
+	// The line covers exactly the pixels from the first to the last point.
+	got := toNRGBA(t, img)
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			want := black
+			if y == 3 && x >= 1 && x <= 6 {
+				want = white
+			}
+			if c := got.NRGBAAt(x, y); c != want {
+				t.Errorf("pixel (%d, %d) mismatch; expected %v, got %v", x, y, want, c)
+			}
+		}
+	}
+}
+
+func TestFillCircle(t *testing.T) {
+	black := color.NRGBA{A: 0xFF}
+	red := color.NRGBA{R: 0xFF, A: 0xFF}
+	img := drawTest(t, 16, 16, black)
+	if err := img.FillCircle(image.Pt(8, 8), 4, red); err != nil {
+		t.Fatal(err)
+	}
+	got := toNRGBA(t, img)
+	if c := got.NRGBAAt(8, 8); c != red {
+		t.Errorf("center pixel mismatch; expected %v, got %v", red, c)
+	}
+	// The pixels at the radius are covered, apart from the curvature of the
+	// edge.
+	for _, p := range []image.Point{{12, 8}, {4, 8}, {8, 12}, {8, 4}} {
+		if c := got.NRGBAAt(p.X, p.Y); c.R < 0xF0 {
+			t.Errorf("pixel %v at radius mismatch; expected nearly red, got %v", p, c)
+		}
+	}
+	for _, p := range []image.Point{{14, 8}, {8, 2}, {0, 0}, {13, 13}} {
+		if c := got.NRGBAAt(p.X, p.Y); c != black {
+			t.Errorf("outside pixel %v mismatch; expected %v, got %v", p, black, c)
+		}
+	}
+	// The edge is anti-aliased.
+	if c := got.NRGBAAt(11, 11); c.R == 0 || c.R == 0xFF {
+		t.Errorf("edge pixel (11, 11) mismatch; expected partial coverage, got %v", c)
+	}
+}
+
+func TestCircle(t *testing.T) {
+	black := color.NRGBA{A: 0xFF}
+	red := color.NRGBA{R: 0xFF, A: 0xFF}
+	img := drawTest(t, 16, 16, black)
+	if err := img.Circle(image.Pt(8, 8), 5, 1, red); err != nil {
+		t.Fatal(err)
+	}
+	got := toNRGBA(t, img)
+	for _, p := range []image.Point{{13, 8}, {3, 8}, {8, 13}, {8, 3}} {
+		if c := got.NRGBAAt(p.X, p.Y); c.R < 0xF0 {
+			t.Errorf("pixel %v on the outline mismatch; expected nearly red, got %v", p, c)
+		}
+	}
+	for _, p := range []image.Point{{8, 8}, {10, 8}, {15, 8}} {
+		if c := got.NRGBAAt(p.X, p.Y); c != black {
+			t.Errorf("pixel %v off the outline mismatch; expected %v, got %v", p, black, c)
+		}
+	}
+}
+
+func TestBlend(t *testing.T) {
+	golden := []struct {
+		name     string
+		dst, src color.NRGBA
+		cov      uint32
+		dstAlpha bool
+		want     color.NRGBA
+	}{
+		{
+			name: "opaque",
+			dst:  color.NRGBA{A: 0xFF}, src: color.NRGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0x80}, cov: 0xFF, dstAlpha: true,
+			want: color.NRGBA{R: 0x80, G: 0x80, B: 0x80, A: 0xFF},
+		},
+		{
+			name: "no alpha channel",
+			dst:  color.NRGBA{A: 0xFF}, src: color.NRGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0x80}, cov: 0xFF,
+			want: color.NRGBA{R: 0x80, G: 0x80, B: 0x80, A: 0xFF},
+		},
+		{
+			name: "partial coverage",
+			dst:  color.NRGBA{A: 0xFF}, src: color.NRGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF}, cov: 0x80, dstAlpha: true,
+			want: color.NRGBA{R: 0x80, G: 0x80, B: 0x80, A: 0xFF},
+		},
+		{
+			name: "transparent",
+			dst:  color.NRGBA{}, src: color.NRGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0x80}, cov: 0xFF, dstAlpha: true,
+			want: color.NRGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0x80},
+		},
+		{
+			name: "translucent",
+			dst:  color.NRGBA{R: 0xFF, A: 0x80}, src: color.NRGBA{B: 0xFF, A: 0x80}, cov: 0xFF, dstAlpha: true,
+			want: color.NRGBA{R: 0x54, B: 0xAA, A: 0xBF},
+		},
+		{
+			name: "no coverage",
+			dst:  color.NRGBA{R: 0xFF, A: 0x80}, src: color.NRGBA{B: 0xFF, A: 0xFF}, cov: 0, dstAlpha: true,
+			want: color.NRGBA{R: 0xFF, A: 0x80},
+		},
+		{
+			name: "transparent source",
+			dst:  color.NRGBA{}, src: color.NRGBA{B: 0xFF}, cov: 0xFF, dstAlpha: true,
+			want: color.NRGBA{},
+		},
+	}
+	for _, g := range golden {
+		if got := blend(g.dst, g.src, g.cov, g.dstAlpha); got != g.want {
+			t.Errorf("%s: color mismatch; expected %v, got %v", g.name, g.want, got)
+		}
+	}
+}
+
+func TestFillPolygonBlend(t *testing.T) {
+	// Blending onto opaque, translucent and transparent destinations.
+	square := []image.Point{{0, 0}, {3, 0}, {3, 3}, {0, 3}}
+	src := color.NRGBA{B: 0xFF, A: 0x80}
+	for _, dst := range []color.NRGBA{{R: 0xFF, A: 0xFF}, {R: 0xFF, A: 0x80}, {}} {
+		img := drawTest(t, 4, 4, dst)
+		if err := img.FillPolygon(square, src); err != nil {
+			t.Fatal(err)
+		}
+		got := toNRGBA(t, img)
+		want := blend(dst, src, 0xFF, true)
+		// The square covers the inner pixels entirely.
+		if c := got.NRGBAAt(1, 1); c != want {
+			t.Errorf("dst %v: pixel (1, 1) mismatch; expected %v, got %v", dst, want, c)
+		}
+	}
+}
+
+func TestPixelFormat(t *testing.T) {
+	rgb565 := &pixelFormat{bpp: 2, masks: [4]uint32{0xF800, 0x07E0, 0x001F, 0}, shifts: [4]uint32{11, 5, 0, 0}}
+	rgb24 := &pixelFormat{bpp: 3, masks: [4]uint32{0xFF0000, 0x00FF00, 0x0000FF, 0}, shifts: [4]uint32{16, 8, 0, 0}}
+	argb8888 := &pixelFormat{bpp: 4, masks: [4]uint32{0x00FF0000, 0x0000FF00, 0x000000FF, 0xFF000000}, shifts: [4]uint32{16, 8, 0, 24}}
+	golden := []struct {
+		name string
+		pf   *pixelFormat
+		c    color.NRGBA
+		// Expected pixel value and color read back.
+		v    uint32
+		want color.NRGBA
+	}{
+		{name: "RGB565 red", pf: rgb565, c: color.NRGBA{R: 0xFF, A: 0xFF}, v: 0xF800, want: color.NRGBA{R: 0xFF, A: 0xFF}},
+		{name: "RGB565 green", pf: rgb565, c: color.NRGBA{G: 0xFF, A: 0x80}, v: 0x07E0, want: color.NRGBA{G: 0xFF, A: 0xFF}},
+		{name: "RGB565 grey", pf: rgb565, c: color.NRGBA{R: 0x80, G: 0x80, B: 0x80, A: 0xFF}, v: 0x8410, want: color.NRGBA{R: 0x83, G: 0x81, B: 0x83, A: 0xFF}},
+		{name: "RGB24", pf: rgb24, c: color.NRGBA{R: 0x12, G: 0x34, B: 0x56, A: 0xFF}, v: 0x123456, want: color.NRGBA{R: 0x12, G: 0x34, B: 0x56, A: 0xFF}},
+		{name: "ARGB8888", pf: argb8888, c: color.NRGBA{R: 0x12, G: 0x34, B: 0x56, A: 0x78}, v: 0x78123456, want: color.NRGBA{R: 0x12, G: 0x34, B: 0x56, A: 0x78}},
+	}
+	for _, g := range golden {
+		// Guard bytes after the pixel must not be overwritten.
+		buf := []byte{0xAA, 0xAA, 0xAA, 0xAA, 0xAA}
+		g.pf.set(buf, g.c)
+		if v := g.pf.load(buf); v != g.v {
+			t.Errorf("%s: pixel value mismatch; expected %#x, got %#x", g.name, g.v, v)
+		}
+		for i := g.pf.bpp; i < len(buf); i++ {
+			if buf[i] != 0xAA {
+				t.Errorf("%s: byte %d overwritten", g.name, i)
+			}
+		}
+		if got := g.pf.get(buf); got != g.want {
+			t.Errorf("%s: color mismatch; expected %v, got %v", g.name, g.want, got)
+		}
+	}
+	// Pixels of 3 bytes are stored in native byte order.
+	buf := make([]byte, 3)
+	rgb24.store(buf, 0x123456)
+	want := []byte{0x56, 0x34, 0x12}
+	if nativeBigEndian {
+		want = []byte{0x12, 0x34, 0x56}
+	}
+	if !bytes.Equal(buf, want) {
+		t.Errorf("RGB24 bytes mismatch; expected %x, got %x", want, buf)
+	}
+}
+
+func TestDrawPixelFormats(t *testing.T) {
+	// Drawing onto images of 16 and 24 bits per pixel gives the same result as
+	// drawing onto the standard image format, up to the precision of the pixel
+	// format.
+	golden := []struct {
+		name                       string
+		depth                      int
+		rmask, gmask, bmask, amask uint32
+		delta                      int
+	}{
+		{name: "RGB565", depth: 16, rmask: 0xF800, gmask: 0x07E0, bmask: 0x001F, delta: 8},
+		{name: "RGB24", depth: 24, rmask: 0xFF0000, gmask: 0x00FF00, bmask: 0x0000FF, delta: 1},
+	}
+	draw := func(img *Image) {
+		if err := img.Line(image.Pt(1, 1), image.Pt(14, 6), 2, color.NRGBA{R: 0xFF, G: 0xC0, A: 0xFF}); err != nil {
+			t.Fatal(err)
+		}
+		if err := img.FillCircle(image.Pt(8, 10), 4, color.NRGBA{B: 0xFF, G: 0x40, A: 0xA0}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	bg := color.NRGBA{R: 0x20, G: 0x40, B: 0x60, A: 0xFF}
+	ref := drawTest(t, 16, 16, bg)
+	draw(ref)
+	want := toNRGBA(t, ref)
+	for _, g := range golden {
+		img, err := drawTest(t, 16, 16, bg).convertMasks(g.depth, g.rmask, g.gmask, g.bmask, g.amask)
+		if err != nil {
+			t.Fatal(err)
+		}
+		draw(img)
+		got := toNRGBA(t, img)
+		img.Free()
+		for y := 0; y < 16; y++ {
+			for x := 0; x < 16; x++ {
+				if colorDiff(want.NRGBAAt(x, y), got.NRGBAAt(x, y)) > g.delta {
+					t.Errorf("%s: pixel (%d, %d) mismatch; expected %v, got %v", g.name, x, y, want.NRGBAAt(x, y), got.NRGBAAt(x, y))
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,19 @@
+package win
+
+// DropBegin is a drag-and-drop event which is triggered when one or more items
+// are about to be dropped onto the window. It is followed by a Drop event.
+type DropBegin struct{}
+
+// Drop is a drag-and-drop event which is triggered when files or text have
+// been dropped onto the window. Items dropped at once are grouped into a single
+// Drop event.
+type Drop struct {
+	// The file paths of dropped files.
+	Files []string
+	// The dropped texts.
+	Texts []string
+}
+
+// drop is the drop in progress, which collects dropped items between
+// SDL_DROPBEGIN and SDL_DROPCOMPLETE events; or nil if no drop is in progress.
+var drop *Drop
@@ -10,6 +10,10 @@ package win
 //    return e->type;
 // }
 //
+// Uint32 getEventTimestamp(SDL_Event *e) {
+//    return e->common.timestamp;
+// }
+//
 // SDL_WindowEvent * getWindowEvent(SDL_Event *e) {
 //    return &e->window;
 // }
@@ -22,6 +26,10 @@ package win
 //    return &e->text;
 // }
 //
+// SDL_TextEditingEvent * getTextEditingEvent(SDL_Event *e) {
+//    return &e->edit;
+// }
+//
 // SDL_MouseMotionEvent * getMouseMotionEvent(SDL_Event *e) {
 //    return &e->motion;
 // }
@@ -33,11 +41,24 @@ package win
 // SDL_MouseWheelEvent * getMouseWheelEvent(SDL_Event *e) {
 //    return &e->wheel;
 // }
+//
+// SDL_DropEvent * getDropEvent(SDL_Event *e) {
+//    return &e->drop;
+// }
+//
+// SDL_TouchFingerEvent * getTouchFingerEvent(SDL_Event *e) {
+//    return &e->tfinger;
+// }
+//
+// SDL_MultiGestureEvent * getMultiGestureEvent(SDL_Event *e) {
+//    return &e->mgesture;
+// }
 import "C"
 
 import (
 	"image"
-	"unicode/utf8"
+	"time"
+	"unsafe"
 
 	"github.com/mewmew/we"
 )
@@ -46,8 +67,37 @@ import (
 // was empty. The various event types are defined at:
 //    github.com/mewmew/we
 //
+// Keyboard events are returned as KeyPress, KeyRepeat and KeyRelease, which
+// extend the corresponding we events with the physical scancode of the key.
+// Note that we.KeyPress, we.KeyRepeat and we.KeyRelease are never returned.
+// Text input is returned as TextInput and TextEdit events; see StartTextInput.
+// Note that we.KeyRune is never returned, as TextInput carries the entire text.
+// Mouse motion which occurs in relative mouse mode is returned as MouseRelMove
+// events; see SetRelativeMouseMode.
+//
+// Events injected through InjectEvent are returned before any pending window
+// events. While an event replay is active, recorded events are returned in
+// place of real input; see StartReplay.
+//
 // Note: PollEvent must be called from the same thread that created the window.
 func PollEvent() (event interface{}) {
+	// Return injected events first.
+	if len(injected) > 0 {
+		event, injected = injected[0], injected[1:]
+		record(ticks(), event)
+		return event
+	}
+	if replay != nil {
+		return replay.poll()
+	}
+	// Return window events which were translated ahead of time.
+	if len(queued) > 0 {
+		next := queued[0]
+		queued = queued[1:]
+		record(next.Time, next.Event)
+		return next.Event
+	}
+
 	e := new(C.SDL_Event)
 	// Poll the event queue until we locate a non-nil event or the queue is
 	// empty.
@@ -58,11 +108,47 @@ func PollEvent() (event interface{}) {
 		}
 		event = goEvent(e)
 		if event != nil {
+			timestamp := time.Duration(C.getEventTimestamp(e)) * time.Millisecond
+			record(timestamp, event)
 			return event
 		}
 	}
 }
 
+// injected is a queue of events which have been injected through InjectEvent.
+var injected []interface{}
+
+// InjectEvent injects the provided event into the event queue. Injected events
+// are returned by PollEvent, in the order they were injected, before any
+// pending window events. The event may be of any type returned by PollEvent.
+func InjectEvent(event interface{}) {
+	injected = append(injected, event)
+}
+
+// queued is a queue of window events which have been removed from the SDL
+// event queue and translated ahead of time by queueEvents.
+var queued []TimedEvent
+
+// queueEvents translates all pending window events and adds them to the queue
+// of events returned by PollEvent. It is used to translate events according to
+// the state at the time they were generated, e.g. before the relative mouse
+// mode changes.
+func queueEvents() {
+	e := new(C.SDL_Event)
+	C.SDL_PumpEvents()
+	for C.SDL_PeepEvents(e, 1, C.SDL_GETEVENT, C.SDL_FIRSTEVENT, C.SDL_LASTEVENT) == 1 {
+		if event := goEvent(e); event != nil {
+			timestamp := time.Duration(C.getEventTimestamp(e)) * time.Millisecond
+			queued = append(queued, TimedEvent{Time: timestamp, Event: event})
+		}
+	}
+}
+
+// ticks returns the time elapsed since the SDL library was initialized.
+func ticks() time.Duration {
+	return time.Duration(C.SDL_GetTicks()) * time.Millisecond
+}
+
 // goEvent returns the corresponding Go event for the provided SDL_Event or nil
 // if no such Go event exists.
 func goEvent(cEvent *C.SDL_Event) (event interface{}) {
@@ -76,7 +162,10 @@ func goEvent(cEvent *C.SDL_Event) (event interface{}) {
 	case C.SDL_WINDOWEVENT:
 		e := C.getWindowEvent(cEvent)
 		switch e.event {
-		case C.SDL_WINDOWEVENT_RESIZED:
+		case C.SDL_WINDOWEVENT_SIZE_CHANGED:
+			// Contrary to SDL_WINDOWEVENT_RESIZED, size changed events are also
+			// triggered when the size is changed programmatically, e.g. by
+			// SetSize or SetFullScreen.
 			event = we.Resize{
 				Width:  int(e.data1),
 				Height: int(e.data2),
@@ -88,38 +177,94 @@ func goEvent(cEvent *C.SDL_Event) (event interface{}) {
 			return we.MouseEnter(false)
 		}
 
+	// Clipboard events.
+	case C.SDL_CLIPBOARDUPDATE:
+		return ClipboardUpdate{}
+
+	// Drag-and-drop events.
+	case C.SDL_DROPBEGIN:
+		drop = new(Drop)
+		return DropBegin{}
+	case C.SDL_DROPFILE, C.SDL_DROPTEXT:
+		e := C.getDropEvent(cEvent)
+		item := C.GoString(e.file)
+		C.SDL_free(unsafe.Pointer(e.file))
+		// Items dropped outside of a SDL_DROPBEGIN and SDL_DROPCOMPLETE pair
+		// are delivered individually.
+		d := drop
+		if d == nil {
+			d = new(Drop)
+		}
+		if typ == C.SDL_DROPFILE {
+			d.Files = append(d.Files, item)
+		} else {
+			d.Texts = append(d.Texts, item)
+		}
+		if drop == nil {
+			return *d
+		}
+	case C.SDL_DROPCOMPLETE:
+		if drop != nil {
+			event = *drop
+			drop = nil
+			return event
+		}
+
 	// Keyboard events.
 	case C.SDL_KEYDOWN:
 		e := C.getKeyboardEvent(cEvent)
+		key := goKey(e.keysym.sym)
+		mod := goMod(C.SDL_Keymod(e.keysym.mod))
+		scancode := Scancode(e.keysym.scancode)
 		if e.repeat == 1 {
-			event = we.KeyRepeat{
-				Key: goKey(e.keysym.sym),
-				Mod: goMod(C.SDL_Keymod(e.keysym.mod)),
+			event = KeyRepeat{
+				KeyRepeat: we.KeyRepeat{Key: key, Mod: mod},
+				Scancode:  scancode,
 			}
 			return event
 		}
-		event = we.KeyPress{
-			Key: goKey(e.keysym.sym),
-			Mod: goMod(C.SDL_Keymod(e.keysym.mod)),
+		event = KeyPress{
+			KeyPress: we.KeyPress{Key: key, Mod: mod},
+			Scancode: scancode,
 		}
 		return event
 	case C.SDL_KEYUP:
 		e := C.getKeyboardEvent(cEvent)
-		event = we.KeyRelease{
-			Key: goKey(e.keysym.sym),
-			Mod: goMod(C.SDL_Keymod(e.keysym.mod)),
+		event = KeyRelease{
+			KeyRelease: we.KeyRelease{
+				Key: goKey(e.keysym.sym),
+				Mod: goMod(C.SDL_Keymod(e.keysym.mod)),
+			},
+			Scancode: Scancode(e.keysym.scancode),
 		}
 		return event
 	case C.SDL_TEXTINPUT:
 		e := C.getTextInputEvent(cEvent)
-		text := C.GoString(&e.text[0])
-		r, _ := utf8.DecodeRuneInString(text)
-		event = we.KeyRune(r)
+		event = TextInput{
+			Text: C.GoString(&e.text[0]),
+		}
+		return event
+	case C.SDL_TEXTEDITING:
+		e := C.getTextEditingEvent(cEvent)
+		event = TextEdit{
+			Text:      C.GoString(&e.text[0]),
+			Cursor:    int(e.start),
+			Selection: int(e.length),
+		}
 		return event
 
 	// Mouse events.
 	case C.SDL_MOUSEMOTION:
 		e := C.getMouseMotionEvent(cEvent)
+		if C.SDL_GetRelativeMouseMode() == C.SDL_TRUE {
+			// The cursor position is meaningless in relative mouse mode.
+			event = MouseRelMove{
+				Rel:     image.Pt(int(e.xrel), int(e.yrel)),
+				Buttons: ButtonState{state: e.state},
+				Mod:     getMod(),
+			}
+			return event
+		}
 		if e.state != 0 {
 			event = we.MouseDrag{
 				Point:  image.Pt(int(e.x), int(e.y)),
@@ -166,8 +311,72 @@ func goEvent(cEvent *C.SDL_Event) (event interface{}) {
 			}
 			return event
 		}
+
+	// Touch events.
+	case C.SDL_FINGERDOWN:
+		e := C.getTouchFingerEvent(cEvent)
+		event = FingerDown{
+			TouchID:  int64(e.touchId),
+			FingerID: int64(e.fingerId),
+			Point:    touchPoint(e.x, e.y),
+			Pressure: float64(e.pressure),
+		}
+		return event
+	case C.SDL_FINGERUP:
+		e := C.getTouchFingerEvent(cEvent)
+		event = FingerUp{
+			TouchID:  int64(e.touchId),
+			FingerID: int64(e.fingerId),
+			Point:    touchPoint(e.x, e.y),
+			Pressure: float64(e.pressure),
+		}
+		return event
+	case C.SDL_FINGERMOTION:
+		e := C.getTouchFingerEvent(cEvent)
+		dx, dy := touchDelta(e.dx, e.dy)
+		event = FingerMove{
+			TouchID:  int64(e.touchId),
+			FingerID: int64(e.fingerId),
+			Point:    touchPoint(e.x, e.y),
+			DX:       dx,
+			DY:       dy,
+			Pressure: float64(e.pressure),
+		}
+		return event
+	case C.SDL_MULTIGESTURE:
+		e := C.getMultiGestureEvent(cEvent)
+		event = MultiGesture{
+			TouchID:  int64(e.touchId),
+			Center:   touchPoint(e.x, e.y),
+			Rotation: float64(e.dTheta),
+			Pinch:    float64(e.dDist),
+			Fingers:  int(e.numFingers),
+		}
+		return event
+	}
+
+	// Consult registered event translators.
+	for _, translate := range translators {
+		event = translate(unsafe.Pointer(cEvent))
+		if event != nil {
+			return event
+		}
 	}
 
 	// Ignore event.
 	return nil
 }
+
+// translators holds the event translators registered through
+// RegisterEventTranslator.
+var translators []func(cEvent unsafe.Pointer) interface{}
+
+// RegisterEventTranslator registers a function which translates SDL events not
+// handled by package win into Go events. The function receives a pointer to an
+// SDL_Event and returns the corresponding Go event, or nil if the event should
+// be ignored. It allows packages such as win/controller to deliver their events
+// through PollEvent. The translated events are recorded only if handled by a
+// codec registered through RegisterEventCodec.
+func RegisterEventTranslator(translate func(cEvent unsafe.Pointer) interface{}) {
+	translators = append(translators, translate)
+}
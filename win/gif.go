@@ -0,0 +1,173 @@
+package win
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"io"
+	"sort"
+	"time"
+)
+
+// A gifEncoder encodes frames as an animated GIF image. The GIF format stores
+// all frames at once, so the quantized frames are kept in memory until the
+// encoding is finished.
+type gifEncoder struct {
+	// Output file or stream.
+	f io.WriteCloser
+	// Quantized frames.
+	anim gif.GIF
+}
+
+// encode quantizes the frame to a palette of 256 colors, using Floyd-Steinberg
+// dithering, and adds it to the animation.
+func (enc *gifEncoder) encode(img *image.NRGBA, d time.Duration) (err error) {
+	bounds := img.Bounds()
+	dst := image.NewPaletted(bounds, quantize(img, 256))
+	draw.FloydSteinberg.Draw(dst, bounds, img, bounds.Min)
+	enc.anim.Image = append(enc.anim.Image, dst)
+	// The frame delay is measured in 100ths of a second.
+	delay := int(d / (10 * time.Millisecond))
+	if delay < 1 {
+		delay = 1
+	}
+	enc.anim.Delay = append(enc.anim.Delay, delay)
+	return nil
+}
+
+// close encodes the animation and closes the output file.
+func (enc *gifEncoder) close() (err error) {
+	defer enc.f.Close()
+	if len(enc.anim.Image) > 0 {
+		err = gif.EncodeAll(enc.f, &enc.anim)
+		if err != nil {
+			return err
+		}
+	}
+	return enc.f.Close()
+}
+
+// quantize returns a palette of at most n colors which approximates the
+// colors of img, using the median cut algorithm. Alpha is ignored.
+func quantize(img *image.NRGBA, n int) (pal color.Palette) {
+	// Build a histogram of the colors, reduced to 5 bits per channel to bound
+	// its size.
+	hist := make(map[[3]uint8]int)
+	bounds := img.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			i := img.PixOffset(x, y)
+			c := [3]uint8{img.Pix[i] >> 3, img.Pix[i+1] >> 3, img.Pix[i+2] >> 3}
+			hist[c]++
+		}
+	}
+	root := &colorBox{}
+	for c, count := range hist {
+		root.colors = append(root.colors, histColor{c: c, count: count})
+	}
+
+	// Repeatedly split the box with the widest channel range at the median of
+	// that channel.
+	boxes := []*colorBox{root}
+	for len(boxes) < n {
+		best, bestRange := -1, 0
+		for i, box := range boxes {
+			if len(box.colors) < 2 {
+				continue
+			}
+			if _, r := box.widest(); r > bestRange {
+				best, bestRange = i, r
+			}
+		}
+		if best == -1 {
+			break
+		}
+		a, b := boxes[best].split()
+		boxes[best] = a
+		boxes = append(boxes, b)
+	}
+
+	// Use the weighted average color of each box.
+	for _, box := range boxes {
+		if len(box.colors) == 0 {
+			continue
+		}
+		var r, g, b, total int
+		for _, hc := range box.colors {
+			r += int(hc.c[0]) * hc.count
+			g += int(hc.c[1]) * hc.count
+			b += int(hc.c[2]) * hc.count
+			total += hc.count
+		}
+		c := color.RGBA{
+			R: expand5(r / total),
+			G: expand5(g / total),
+			B: expand5(b / total),
+			A: 0xFF,
+		}
+		pal = append(pal, c)
+	}
+	return pal
+}
+
+// expand5 expands a 5 bit color channel to 8 bits.
+func expand5(v int) uint8 {
+	return uint8(v<<3 | v>>2)
+}
+
+// A histColor is a color of a histogram together with its pixel count.
+type histColor struct {
+	c     [3]uint8
+	count int
+}
+
+// A colorBox is a box of colors in the median cut algorithm.
+type colorBox struct {
+	colors []histColor
+}
+
+// widest returns the channel with the widest range of the box, and its range.
+func (box *colorBox) widest() (channel, r int) {
+	for ch := 0; ch < 3; ch++ {
+		min, max := uint8(0xFF), uint8(0)
+		for _, hc := range box.colors {
+			if hc.c[ch] < min {
+				min = hc.c[ch]
+			}
+			if hc.c[ch] > max {
+				max = hc.c[ch]
+			}
+		}
+		if int(max-min) > r || ch == 0 {
+			channel, r = ch, int(max-min)
+		}
+	}
+	return channel, r
+}
+
+// split splits the box at the pixel count median of its widest channel.
+func (box *colorBox) split() (a, b *colorBox) {
+	ch, _ := box.widest()
+	sort.Slice(box.colors, func(i, j int) bool {
+		return box.colors[i].c[ch] < box.colors[j].c[ch]
+	})
+	var total int
+	for _, hc := range box.colors {
+		total += hc.count
+	}
+	// Split after the median pixel, keeping at least one color in each box.
+	i, sum := 0, 0
+	for ; i < len(box.colors)-1; i++ {
+		sum += box.colors[i].count
+		if sum*2 >= total {
+			break
+		}
+	}
+	if i > len(box.colors)-2 {
+		i = len(box.colors) - 2
+	}
+	a = &colorBox{colors: box.colors[:i+1]}
+	b = &colorBox{colors: box.colors[i+1:]}
+	return a, b
+}
@@ -0,0 +1,71 @@
+package win
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestQuantize(t *testing.T) {
+	// Channel values which are unchanged by the reduction to 5 bits.
+	black := color.RGBA{A: 0xFF}
+	white := color.RGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF}
+	red := color.RGBA{R: 0xFF, A: 0xFF}
+	grey := color.RGBA{R: 0x84, G: 0x84, B: 0x84, A: 0xFF}
+	golden := []struct {
+		colors []color.RGBA
+		n      int
+		// Expected palette size.
+		want int
+	}{
+		{colors: []color.RGBA{black}, n: 256, want: 1},
+		{colors: []color.RGBA{black, white, red, grey}, n: 256, want: 4},
+		{colors: []color.RGBA{black, white, red, grey}, n: 2, want: 2},
+		{colors: []color.RGBA{black, black, black, white}, n: 256, want: 2},
+	}
+	for _, g := range golden {
+		img := image.NewNRGBA(image.Rect(0, 0, len(g.colors), 1))
+		for x, c := range g.colors {
+			img.Set(x, 0, c)
+		}
+		pal := quantize(img, g.n)
+		if len(pal) != g.want {
+			t.Errorf("%v, n=%d: palette size mismatch; expected %d, got %d", g.colors, g.n, g.want, len(pal))
+			continue
+		}
+		// Colors are kept exactly when there is room for all of them.
+		if g.n < len(g.colors) {
+			continue
+		}
+		for _, c := range g.colors {
+			if got := pal.Convert(c); got != c {
+				t.Errorf("%v, n=%d: color %v mismatch; got %v", g.colors, g.n, c, got)
+			}
+		}
+	}
+}
+
+func TestQuantizeSplit(t *testing.T) {
+	// Two clusters of colors, a dark one and a light one, are quantized to one
+	// color each.
+	dark := []color.RGBA{{A: 0xFF}, {R: 0x10, A: 0xFF}, {G: 0x10, A: 0xFF}}
+	light := []color.RGBA{{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF}, {R: 0xEF, G: 0xFF, B: 0xFF, A: 0xFF}}
+	img := image.NewNRGBA(image.Rect(0, 0, len(dark)+len(light), 1))
+	for x, c := range append(dark, light...) {
+		img.Set(x, 0, c)
+	}
+	pal := quantize(img, 2)
+	if len(pal) != 2 {
+		t.Fatalf("palette size mismatch; expected 2, got %d", len(pal))
+	}
+	for _, c := range dark {
+		if got := pal.Convert(c).(color.RGBA); got.R > 0x20 || got.G > 0x20 || got.B > 0x20 {
+			t.Errorf("dark color %v mismatch; got %v", c, got)
+		}
+	}
+	for _, c := range light {
+		if got := pal.Convert(c).(color.RGBA); got.R < 0xE0 || got.G < 0xE0 || got.B < 0xE0 {
+			t.Errorf("light color %v mismatch; got %v", c, got)
+		}
+	}
+}
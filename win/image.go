@@ -1,17 +1,26 @@
 package win
 
 // #cgo pkg-config: sdl2
-// #include <string.h>
+// #include <stdlib.h>
 // #include <SDL2/SDL.h>
 import "C"
 
 import (
+	"errors"
 	"image"
+	"image/color"
 	"image/draw"
-	"reflect"
+	// Register the image formats supported by DecodeImage.
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"io/fs"
+	"os"
 	"unsafe"
 
-	"github.com/mewkiz/pkg/imgutil"
+	_ "golang.org/x/image/bmp"
+	_ "golang.org/x/image/webp"
 )
 
 // An Image is a collection of pixels.
@@ -50,11 +59,41 @@ func NewImage(width, height int) (img *Image, err error) {
 	return img, nil
 }
 
-// LoadImage loads the provided image file and returns it as an image.
+// LoadImage loads the provided image file and returns it as an image. The
+// supported image formats are listed in the documentation of DecodeImage.
 //
 // Note: The Free method of the image should be called when finished using it.
 func LoadImage(imgPath string) (img *Image, err error) {
-	src, err := imgutil.ReadFile(imgPath)
+	f, err := os.Open(imgPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return DecodeImage(f)
+}
+
+// LoadImageFS loads the named image file of the provided file system and
+// returns it as an image, e.g. to load images embedded using package embed.
+// The supported image formats are listed in the documentation of DecodeImage.
+//
+// Note: The Free method of the image should be called when finished using it.
+func LoadImageFS(fsys fs.FS, name string) (img *Image, err error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return DecodeImage(f)
+}
+
+// DecodeImage decodes the image read from r and returns it as an image. The
+// image format is detected automatically. The PNG, JPEG, GIF, BMP, WebP, TGA
+// and QOI image formats are supported, as well as any other image format
+// registered using image.RegisterFormat.
+//
+// Note: The Free method of the image should be called when finished using it.
+func DecodeImage(r io.Reader) (img *Image, err error) {
+	src, _, err := image.Decode(r)
 	if err != nil {
 		return nil, err
 	}
@@ -64,6 +103,10 @@ func LoadImage(imgPath string) (img *Image, err error) {
 // ReadImage reads the provided image, converts it to the standard image format
 // of this library and returns it.
 //
+// The pixels of the standard image format are stored with non-premultiplied
+// alpha, like image.NRGBA. Images with premultiplied alpha, such as
+// image.RGBA, are converted accordingly.
+//
 // Note: The Free method of the image should be called when finished using it.
 func ReadImage(src image.Image) (img *Image, err error) {
 	rect := src.Bounds()
@@ -72,56 +115,166 @@ func ReadImage(src image.Image) (img *Image, err error) {
 	if err != nil {
 		return nil, err
 	}
-	var pix []uint8
+	if rect.Empty() {
+		return img, nil
+	}
+	dst := img.nrgbaView()
 	switch i := src.(type) {
 	case *image.NRGBA:
-		pix = i.Pix
+		copyLines(dst, i.Pix[i.PixOffset(rect.Min.X, rect.Min.Y):], i.Stride)
 	case *image.RGBA:
-		// TODO(u): Do we need normalize the image since its stored as
-		// premultiplied alpha? If so divide the color values by the alpha value.
-		// An alternative is to use the default fallback. If so benchmark first.
-		pix = i.Pix
+		// Premultiplied and non-premultiplied alpha only differ for pixels
+		// which are not fully opaque.
+		if i.Opaque() {
+			copyLines(dst, i.Pix[i.PixOffset(rect.Min.X, rect.Min.Y):], i.Stride)
+		} else {
+			unpremultiply(dst, i)
+		}
 	default:
-		copyPixels(img, src)
-		return img, nil
+		copyPixels(dst, src)
 	}
-	C.memcpy(img.s.pixels, unsafe.Pointer(&pix[0]), C.size_t(len(pix)))
 	return img, nil
 }
 
-// copyPixels copies the pixels of the src image to the dst SDL surface. It uses
-// unsafe to draw directly to the memory of the SDL surface. No alpha blending
-// is performed since it's always used during the creation of new SDL surfaces.
+// nrgbaView returns an image.NRGBA which points to the memory of the pixels of
+// the image.
 //
-// Note: The dst image must be a valid SDL surface created with NewImage.
-func copyPixels(dst *Image, src image.Image) {
-	// stride is the size in bytes of each line. The size of an individual
-	// pixel is 4 bytes.
-	stride := dst.Width * 4
-	// size is the total size in bytes of the pixel data.
-	size := dst.Height * stride
-	sh := reflect.SliceHeader{
-		Data: uintptr(dst.s.pixels),
-		Len:  size,
-		Cap:  size,
-	}
-	// dstPix is a byte slice which points to the memory of the surface's pixels.
-	dstPix := *(*[]byte)(unsafe.Pointer(&sh))
-
-	dstRect := image.Rect(0, 0, dst.Width, dst.Height)
-	dstImg := &image.NRGBA{
-		Pix:    dstPix,
-		Stride: stride,
-		Rect:   dstRect,
-	}
-	draw.Draw(dstImg, dstRect, src, image.ZP, draw.Src)
+// Note: The image must be a valid SDL surface created with NewImage, whose
+// byte order matches image.NRGBA.
+func (img *Image) nrgbaView() *image.NRGBA {
+	// The pitch of the surface is the size in bytes of each line.
+	pitch := int(img.s.pitch)
+	return &image.NRGBA{
+		Pix:    surfacePix(img.s.pixels, img.Height*pitch),
+		Stride: pitch,
+		Rect:   image.Rect(0, 0, img.Width, img.Height),
+	}
+}
+
+// copyLines copies the lines of pixels in the NRGBA byte order from src, which
+// has the provided stride, to dst.
+func copyLines(dst *image.NRGBA, src []uint8, stride int) {
+	n := dst.Rect.Dx() * 4
+	for y := 0; y < dst.Rect.Dy(); y++ {
+		copy(dst.Pix[y*dst.Stride:y*dst.Stride+n], src[y*stride:y*stride+n])
+	}
+}
+
+// unpremultiply copies the pixels of the src image to dst, converting them
+// from premultiplied to non-premultiplied alpha.
+func unpremultiply(dst *image.NRGBA, src *image.RGBA) {
+	min := src.Bounds().Min
+	n := dst.Rect.Dx() * 4
+	for y := 0; y < dst.Rect.Dy(); y++ {
+		d := dst.Pix[y*dst.Stride : y*dst.Stride+n]
+		i := src.PixOffset(min.X, min.Y+y)
+		s := src.Pix[i : i+n]
+		for j := 0; j < n; j += 4 {
+			switch a := uint32(s[j+3]); a {
+			case 0:
+				d[j], d[j+1], d[j+2], d[j+3] = 0, 0, 0, 0
+			case 0xFF:
+				copy(d[j:j+4], s[j:j+4])
+			default:
+				// Divide in 16 bits, like color.NRGBAModel, to round
+				// identically to the generic conversion.
+				d[j] = uint8(uint32(s[j]) * 0xFFFF / a >> 8)
+				d[j+1] = uint8(uint32(s[j+1]) * 0xFFFF / a >> 8)
+				d[j+2] = uint8(uint32(s[j+2]) * 0xFFFF / a >> 8)
+				d[j+3] = uint8(a)
+			}
+		}
+	}
+}
+
+// copyPixels copies the pixels of the src image to dst, using the generic
+// conversion of the image/draw package. No alpha blending is performed since
+// it's always used during the creation of new SDL surfaces.
+func copyPixels(dst *image.NRGBA, src image.Image) {
+	draw.Draw(dst, dst.Rect, src, src.Bounds().Min, draw.Src)
+}
+
+// surfaceNRGBA returns a copy of the pixels of the provided SDL surface, which
+// may be of any pixel format.
+func surfaceNRGBA(s *C.SDL_Surface) (img *image.NRGBA, err error) {
+	// Convert the surface to a pixel format with the byte order of
+	// image.NRGBA, i.e. R, G, B, A regardless of the native byte order.
+	conv := C.SDL_ConvertSurfaceFormat(s, C.SDL_PIXELFORMAT_RGBA32, 0)
+	if conv == nil {
+		return nil, getError()
+	}
+	defer C.SDL_FreeSurface(conv)
+	if C.SDL_LockSurface(conv) != 0 {
+		return nil, getError()
+	}
+	defer C.SDL_UnlockSurface(conv)
+
+	width, height := int(conv.w), int(conv.h)
+	img = image.NewNRGBA(image.Rect(0, 0, width, height))
+	// The pitch of the surface may exceed the width of a line.
+	pitch := int(conv.pitch)
+	srcPix := surfacePix(conv.pixels, pitch*height)
+	for y := 0; y < height; y++ {
+		copy(img.Pix[y*img.Stride:(y+1)*img.Stride], srcPix[y*pitch:])
+	}
+	return img, nil
+}
+
+// surfacePix returns a byte slice of the provided length, which points to the
+// pixels of an SDL surface.
+func surfacePix(pixels unsafe.Pointer, size int) []byte {
+	return unsafe.Slice((*byte)(pixels), size)
 }
 
 // Free frees the image.
 func (img *Image) Free() {
+	if root, ok := viewRoots[img.s]; ok {
+		delete(viewRoots, img.s)
+		if viewCounts[root] > 0 {
+			viewCounts[root]--
+		}
+		if viewCounts[root] == 0 {
+			delete(viewCounts, root)
+		}
+	} else {
+		delete(viewCounts, img.s)
+	}
 	C.SDL_FreeSurface(img.s)
 }
 
+// ToNRGBA returns a copy of the pixels of the image as a Go image, e.g. to
+// inspect or encode images rendered by package win or package font.
+func (img *Image) ToNRGBA() (dst *image.NRGBA, err error) {
+	return surfaceNRGBA(img.s)
+}
+
+// SavePNG saves the image as a PNG image file.
+func (img *Image) SavePNG(pngPath string) (err error) {
+	src, err := img.ToNRGBA()
+	if err != nil {
+		return err
+	}
+	return writePNG(pngPath, src)
+}
+
+// SaveBMP saves the image as a BMP image file. Images with an alpha channel are
+// stored as 32-bit BMP images, which not all decoders support.
+func (img *Image) SaveBMP(bmpPath string) (err error) {
+	cBmpPath := C.CString(bmpPath)
+	defer C.free(unsafe.Pointer(cBmpPath))
+	cMode := C.CString("wb")
+	defer C.free(unsafe.Pointer(cMode))
+	rw := C.SDL_RWFromFile(cBmpPath, cMode)
+	if rw == nil {
+		return getError()
+	}
+	// The SDL_RWops is closed by SDL_SaveBMP_RW.
+	if C.SDL_SaveBMP_RW(img.s, rw, 1) != 0 {
+		return getError()
+	}
+	return nil
+}
+
 // Draw draws the entire src image onto the dst image starting at the
 // destination point dp.
 func (dst *Image) Draw(dp image.Point, src *Image) (err error) {
@@ -130,7 +283,8 @@ func (dst *Image) Draw(dp image.Point, src *Image) (err error) {
 }
 
 // DrawRect fills the destination rectangle dr of the dst image with
-// corresponding pixels from the src image starting at the source point sp.
+// corresponding pixels from the src image starting at the source point sp. The
+// pixels are not scaled; see DrawScaled.
 func (dst *Image) DrawRect(dr image.Rectangle, src *Image, sp image.Point) (err error) {
 	sr := image.Rect(sp.X, sp.Y, sp.X+dr.Dx(), sp.Y+dr.Dy())
 	srcRect := cRect(sr)
@@ -140,3 +294,199 @@ func (dst *Image) DrawRect(dr image.Rectangle, src *Image, sp image.Point) (err
 	}
 	return nil
 }
+
+// BlendMode specifies how the pixels of a source image are combined with the
+// pixels of a destination image when drawn.
+type BlendMode int
+
+// Blend modes.
+const (
+	// BlendNone copies the source pixels, including alpha, onto the
+	// destination.
+	//    dstRGBA = srcRGBA
+	BlendNone BlendMode = C.SDL_BLENDMODE_NONE
+	// BlendAlpha performs alpha blending of the source pixels onto the
+	// destination. This is the default blend mode of images with an alpha
+	// channel.
+	//    dstRGB = srcRGB*srcA + dstRGB*(1-srcA)
+	//    dstA = srcA + dstA*(1-srcA)
+	BlendAlpha BlendMode = C.SDL_BLENDMODE_BLEND
+	// BlendAdd performs additive blending of the source pixels onto the
+	// destination, e.g. for light and particle effects.
+	//    dstRGB = srcRGB*srcA + dstRGB
+	//    dstA = dstA
+	BlendAdd BlendMode = C.SDL_BLENDMODE_ADD
+	// BlendMod modulates the destination with the source pixels, e.g. for
+	// tinting and shadows.
+	//    dstRGB = srcRGB*dstRGB
+	//    dstA = dstA
+	BlendMod BlendMode = C.SDL_BLENDMODE_MOD
+)
+
+// SetBlendMode sets the blend mode used when drawing the image onto other
+// images.
+func (img *Image) SetBlendMode(mode BlendMode) (err error) {
+	if C.SDL_SetSurfaceBlendMode(img.s, C.SDL_BlendMode(mode)) != 0 {
+		return getError()
+	}
+	return nil
+}
+
+// SetColorKey sets the color key of the image. Pixels of the color key color
+// are treated as transparent when drawing the image onto other images. The
+// alpha channel of the color key is ignored.
+func (img *Image) SetColorKey(c color.Color) (err error) {
+	nc := color.NRGBAModel.Convert(c).(color.NRGBA)
+	key := C.SDL_MapRGB(img.s.format, C.Uint8(nc.R), C.Uint8(nc.G), C.Uint8(nc.B))
+	if C.SDL_SetColorKey(img.s, C.SDL_TRUE, key) != 0 {
+		return getError()
+	}
+	return nil
+}
+
+// ClearColorKey clears the color key of the image.
+func (img *Image) ClearColorKey() (err error) {
+	if C.SDL_SetColorKey(img.s, C.SDL_FALSE, 0) != 0 {
+		return getError()
+	}
+	return nil
+}
+
+// SetAlphaMod sets the alpha modulation of the image. The alpha channel of
+// each source pixel is multiplied by alpha/255 when drawing the image onto
+// other images, e.g. to fade sprites in and out.
+func (img *Image) SetAlphaMod(alpha uint8) (err error) {
+	if C.SDL_SetSurfaceAlphaMod(img.s, C.Uint8(alpha)) != 0 {
+		return getError()
+	}
+	return nil
+}
+
+// SetColorMod sets the color modulation of the image. Each color channel of
+// each source pixel is multiplied by the corresponding channel value/255 when
+// drawing the image onto other images, e.g. to tint sprites.
+func (img *Image) SetColorMod(r, g, b uint8) (err error) {
+	if C.SDL_SetSurfaceColorMod(img.s, C.Uint8(r), C.Uint8(g), C.Uint8(b)) != 0 {
+		return getError()
+	}
+	return nil
+}
+
+// SubImage returns an image which is a view of the portion of the image
+// visible through r. The returned image shares its pixels with the original
+// image, and inherits its blend mode, color key, and alpha and color
+// modulation. It may be drawn like any other image, e.g. to draw a single
+// sprite of a sprite sheet.
+//
+// RLE acceleration can not be enabled for the original image while the
+// returned image is in use; see Image.SetRLE.
+//
+// Note: The Free method of the returned image should be called when finished
+// using it, and before the original image is freed.
+func (img *Image) SubImage(r image.Rectangle) (sub *Image, err error) {
+	r = r.Intersect(image.Rect(0, 0, img.Width, img.Height))
+	if r.Empty() {
+		return nil, errors.New("win.Image.SubImage: empty rectangle")
+	}
+	if C.SDL_HasSurfaceRLE(img.s) == C.SDL_TRUE {
+		return nil, errors.New("win.Image.SubImage: unable to create view of RLE accelerated image")
+	}
+
+	// Create a surface which points into the pixels of the original surface.
+	// The pixels are not freed when the surface is freed.
+	format := img.s.format
+	offset := uintptr(r.Min.Y)*uintptr(img.s.pitch) + uintptr(r.Min.X)*uintptr(format.BytesPerPixel)
+	pixels := unsafe.Pointer(uintptr(img.s.pixels) + offset)
+	s := C.SDL_CreateRGBSurfaceWithFormatFrom(pixels, C.int(r.Dx()), C.int(r.Dy()), C.int(format.BitsPerPixel), img.s.pitch, format.format)
+	if s == nil {
+		return nil, getError()
+	}
+	sub = &Image{
+		Width:  r.Dx(),
+		Height: r.Dy(),
+		s:      s,
+	}
+	// Track the view of the surface which owns the pixels.
+	root := img.s
+	if parent, ok := viewRoots[img.s]; ok {
+		root = parent
+	}
+	viewRoots[s] = root
+	viewCounts[root]++
+	err = sub.inherit(img)
+	if err != nil {
+		sub.Free()
+		return nil, err
+	}
+	return sub, nil
+}
+
+// viewRoots maps the surfaces of sub-image views to the surfaces which own
+// their pixels, and viewCounts maps the latter to their number of live views.
+var (
+	viewRoots  = make(map[*C.SDL_Surface]*C.SDL_Surface)
+	viewCounts = make(map[*C.SDL_Surface]int)
+)
+
+// inherit copies the palette, blend mode, color key, and alpha and color
+// modulation of the parent image to the image.
+func (img *Image) inherit(parent *Image) (err error) {
+	if parent.s.format.palette != nil {
+		if C.SDL_SetSurfacePalette(img.s, parent.s.format.palette) != 0 {
+			return getError()
+		}
+	}
+	var mode C.SDL_BlendMode
+	if C.SDL_GetSurfaceBlendMode(parent.s, &mode) != 0 {
+		return getError()
+	}
+	if C.SDL_SetSurfaceBlendMode(img.s, mode) != 0 {
+		return getError()
+	}
+	var key C.Uint32
+	if C.SDL_GetColorKey(parent.s, &key) == 0 {
+		if C.SDL_SetColorKey(img.s, C.SDL_TRUE, key) != 0 {
+			return getError()
+		}
+	}
+	return img.inheritMods(parent)
+}
+
+// inheritConverted copies the blend mode, and alpha and color modulation of the
+// parent image to the image, which is a copy of the parent converted to
+// another pixel format. The color key of the parent is not copied, as the
+// conversion either turns it into transparent pixels, for pixel formats with an
+// alpha channel, or preserves it. Neither is the default blend mode of parents
+// without an alpha channel, as it would ignore the alpha channel of the copy.
+func (img *Image) inheritConverted(parent *Image) (err error) {
+	var mode C.SDL_BlendMode
+	if C.SDL_GetSurfaceBlendMode(parent.s, &mode) != 0 {
+		return getError()
+	}
+	if mode != C.SDL_BLENDMODE_NONE || parent.s.format.Amask != 0 {
+		if C.SDL_SetSurfaceBlendMode(img.s, mode) != 0 {
+			return getError()
+		}
+	}
+	return img.inheritMods(parent)
+}
+
+// inheritMods copies the alpha and color modulation of the parent image to the
+// image.
+func (img *Image) inheritMods(parent *Image) (err error) {
+	var alpha C.Uint8
+	if C.SDL_GetSurfaceAlphaMod(parent.s, &alpha) != 0 {
+		return getError()
+	}
+	if C.SDL_SetSurfaceAlphaMod(img.s, alpha) != 0 {
+		return getError()
+	}
+	var r, g, b C.Uint8
+	if C.SDL_GetSurfaceColorMod(parent.s, &r, &g, &b) != 0 {
+		return getError()
+	}
+	if C.SDL_SetSurfaceColorMod(img.s, r, g, b) != 0 {
+		return getError()
+	}
+	return nil
+}
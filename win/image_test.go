@@ -0,0 +1,164 @@
+package win
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// fillTest fills img with a distinct color for each pixel. The alpha channel
+// ranges over all values if translucent is true, and is opaque otherwise.
+func fillTest(img interface{ Set(x, y int, c color.Color) }, r image.Rectangle, translucent bool) {
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			c := color.NRGBA{R: uint8(x * 7), G: uint8(y * 13), B: uint8(x*y + 3), A: 0xFF}
+			if translucent {
+				c.A = uint8(x*31 + y*17)
+			}
+			img.Set(x, y, c)
+		}
+	}
+}
+
+func TestSavePNG(t *testing.T) {
+	// The pixels, including translucent ones, are preserved.
+	src := image.NewNRGBA(image.Rect(0, 0, 5, 3))
+	fillTest(src, src.Bounds(), true)
+	img := newTestImage(t, src)
+	pngPath := filepath.Join(t.TempDir(), "out.png")
+	if err := img.SavePNG(pngPath); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(pngPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	got, err := png.Decode(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkRoundTrip(t, "PNG", got, src)
+}
+
+func TestSaveBMP(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(0, 0, 5, 3))
+	fillTest(src, src.Bounds(), true)
+	img := newTestImage(t, src)
+	bmpPath := filepath.Join(t.TempDir(), "out.bmp")
+	if err := img.SaveBMP(bmpPath); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadImage(bmpPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer loaded.Free()
+	checkRoundTrip(t, "BMP", toNRGBA(t, loaded), src)
+}
+
+// checkRoundTrip reports an error unless the pixels of got equal those of want.
+func checkRoundTrip(t *testing.T, name string, got image.Image, want *image.NRGBA) {
+	t.Helper()
+	if got.Bounds() != want.Bounds() {
+		t.Fatalf("%s: bounds mismatch; expected %v, got %v", name, want.Bounds(), got.Bounds())
+	}
+	dst := image.NewNRGBA(want.Bounds())
+	for y := 0; y < want.Rect.Dy(); y++ {
+		for x := 0; x < want.Rect.Dx(); x++ {
+			dst.Set(x, y, got.At(x, y))
+		}
+	}
+	if !reflect.DeepEqual(dst.Pix, want.Pix) {
+		t.Errorf("%s: pixels mismatch; expected %v, got %v", name, want.Pix, dst.Pix)
+	}
+}
+
+func TestReadImageSubImage(t *testing.T) {
+	r := image.Rect(0, 0, 8, 6)
+	nrgba := image.NewNRGBA(r)
+	fillTest(nrgba, r, true)
+	opaque := image.NewRGBA(r)
+	fillTest(opaque, r, false)
+	premul := image.NewRGBA(r)
+	fillTest(premul, r, true)
+	generic := image.NewNRGBA64(r)
+	fillTest(generic, r, true)
+
+	// The sub-images have a non-zero origin, and a stride which exceeds the
+	// size of their lines.
+	sr := image.Rect(2, 1, 6, 5)
+	golden := []struct {
+		name string
+		src  image.Image
+	}{
+		{name: "NRGBA", src: nrgba.SubImage(sr)},
+		{name: "opaque RGBA", src: opaque.SubImage(sr)},
+		{name: "premultiplied RGBA", src: premul.SubImage(sr)},
+		{name: "generic", src: generic.SubImage(sr)},
+	}
+	for _, g := range golden {
+		img, err := ReadImage(g.src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := toNRGBA(t, img)
+		img.Free()
+		if size := got.Bounds().Size(); size != sr.Size() {
+			t.Errorf("%s: size mismatch; expected %v, got %v", g.name, sr.Size(), size)
+			continue
+		}
+		for y := 0; y < sr.Dy(); y++ {
+			for x := 0; x < sr.Dx(); x++ {
+				want := color.NRGBAModel.Convert(g.src.At(sr.Min.X+x, sr.Min.Y+y)).(color.NRGBA)
+				if c := got.NRGBAAt(x, y); c != want {
+					t.Errorf("%s: pixel (%d, %d) mismatch; expected %v, got %v", g.name, x, y, want, c)
+				}
+			}
+		}
+	}
+}
+
+// benchmarkReadImage benchmarks ReadImage of the provided image.
+func benchmarkReadImage(b *testing.B, src image.Image) {
+	b.SetBytes(int64(src.Bounds().Dx() * src.Bounds().Dy() * 4))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		img, err := ReadImage(src)
+		if err != nil {
+			b.Fatal(err)
+		}
+		img.Free()
+	}
+}
+
+// benchRect is the size of the images of the ReadImage benchmarks.
+var benchRect = image.Rect(0, 0, 256, 256)
+
+func BenchmarkReadImageNRGBA(b *testing.B) {
+	src := image.NewNRGBA(benchRect)
+	fillTest(src, benchRect, true)
+	benchmarkReadImage(b, src)
+}
+
+func BenchmarkReadImageOpaque(b *testing.B) {
+	src := image.NewRGBA(benchRect)
+	fillTest(src, benchRect, false)
+	benchmarkReadImage(b, src)
+}
+
+func BenchmarkReadImageUnpremultiply(b *testing.B) {
+	src := image.NewRGBA(benchRect)
+	fillTest(src, benchRect, true)
+	benchmarkReadImage(b, src)
+}
+
+func BenchmarkReadImageGeneric(b *testing.B) {
+	src := image.NewNRGBA64(benchRect)
+	fillTest(src, benchRect, true)
+	benchmarkReadImage(b, src)
+}
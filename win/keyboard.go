@@ -5,9 +5,78 @@ package win
 import "C"
 
 import (
+	"unsafe"
+
 	"github.com/mewmew/we"
 )
 
+// KeyPress is a keyboard event which is triggered when a key is pressed. In
+// addition to the layout dependent key of we.KeyPress it carries the physical
+// scancode of the key.
+type KeyPress struct {
+	we.KeyPress
+	// The physical scancode of the key.
+	Scancode Scancode
+}
+
+// KeyRepeat is a keyboard event which is triggered when a key is held down long
+// enough to repeat. In addition to the layout dependent key of we.KeyRepeat it
+// carries the physical scancode of the key.
+type KeyRepeat struct {
+	we.KeyRepeat
+	// The physical scancode of the key.
+	Scancode Scancode
+}
+
+// KeyRelease is a keyboard event which is triggered when a key is released. In
+// addition to the layout dependent key of we.KeyRelease it carries the physical
+// scancode of the key.
+type KeyRelease struct {
+	we.KeyRelease
+	// The physical scancode of the key.
+	Scancode Scancode
+}
+
+// KeyState is a snapshot of the keyboard state, as returned by KeyboardState.
+type KeyState struct {
+	// The active keyboard modifiers.
+	Mod we.Mod
+	// pressed is indexed by scancode and reports whether the key is held down.
+	pressed []bool
+}
+
+// KeyboardState returns a snapshot of the current keyboard state. It is updated
+// as events are processed, and therefore reflects the state of the keyboard as
+// of the last call to PollEvent.
+func KeyboardState() (state KeyState) {
+	var n C.int
+	p := C.SDL_GetKeyboardState(&n)
+	// cState points to the SDL owned array of key states, which is indexed by
+	// scancode.
+	cState := (*[1 << 16]C.Uint8)(unsafe.Pointer(p))[:n:n]
+	state.pressed = make([]bool, n)
+	for i, v := range cState {
+		state.pressed[i] = v != 0
+	}
+	state.Mod = getMod()
+	return state
+}
+
+// IsDown returns true if the provided key is held down in the current keyboard
+// layout, and false otherwise.
+func (state KeyState) IsDown(key we.Key) bool {
+	return state.IsScancodeDown(ScancodeFromKey(key))
+}
+
+// IsScancodeDown returns true if the key at the physical location of the
+// provided scancode is held down, and false otherwise.
+func (state KeyState) IsScancodeDown(scancode Scancode) bool {
+	if scancode < 0 || int(scancode) >= len(state.pressed) {
+		return false
+	}
+	return state.pressed[scancode]
+}
+
 // getMod returns the currently active keyboard modifiers.
 func getMod() (mod we.Mod) {
 	return goMod(C.SDL_GetModState())
@@ -32,246 +101,145 @@ func goMod(m C.SDL_Keymod) (mod we.Mod) {
 
 // goKey returns the corresponding we.Key for the provided SDL_Keycode.
 func goKey(keycode C.SDL_Keycode) (key we.Key) {
-	switch keycode {
+	// Unknown keys are mapped to 0.
+	return keys[keycode]
+}
+
+// cKey returns the corresponding SDL_Keycode for the provided we.Key.
+func cKey(key we.Key) (keycode C.SDL_Keycode) {
+	// Unknown keys are mapped to SDLK_UNKNOWN.
+	return keycodes[key]
+}
+
+// keys maps from SDL keycodes to the corresponding we.Key.
+var keys = map[C.SDL_Keycode]we.Key{
 	// Printable keys.
-	case C.SDLK_SPACE:
-		return we.KeySpace
-	case C.SDLK_QUOTE:
-		return we.KeyApostrophe
-	case C.SDLK_COMMA:
-		return we.KeyComma
-	case C.SDLK_MINUS:
-		return we.KeyMinus
-	case C.SDLK_PERIOD:
-		return we.KeyPeriod
-	case C.SDLK_SLASH:
-		return we.KeySlash
-	case C.SDLK_0:
-		return we.Key0
-	case C.SDLK_1:
-		return we.Key1
-	case C.SDLK_2:
-		return we.Key2
-	case C.SDLK_3:
-		return we.Key3
-	case C.SDLK_4:
-		return we.Key4
-	case C.SDLK_5:
-		return we.Key5
-	case C.SDLK_6:
-		return we.Key6
-	case C.SDLK_7:
-		return we.Key7
-	case C.SDLK_8:
-		return we.Key8
-	case C.SDLK_9:
-		return we.Key9
-	case C.SDLK_SEMICOLON:
-		return we.KeySemicolon
-	case C.SDLK_EQUALS:
-		return we.KeyEqual
-	case C.SDLK_a:
-		return we.KeyA
-	case C.SDLK_b:
-		return we.KeyB
-	case C.SDLK_c:
-		return we.KeyC
-	case C.SDLK_d:
-		return we.KeyD
-	case C.SDLK_e:
-		return we.KeyE
-	case C.SDLK_f:
-		return we.KeyF
-	case C.SDLK_g:
-		return we.KeyG
-	case C.SDLK_h:
-		return we.KeyH
-	case C.SDLK_i:
-		return we.KeyI
-	case C.SDLK_j:
-		return we.KeyJ
-	case C.SDLK_k:
-		return we.KeyK
-	case C.SDLK_l:
-		return we.KeyL
-	case C.SDLK_m:
-		return we.KeyM
-	case C.SDLK_n:
-		return we.KeyN
-	case C.SDLK_o:
-		return we.KeyO
-	case C.SDLK_p:
-		return we.KeyP
-	case C.SDLK_q:
-		return we.KeyQ
-	case C.SDLK_r:
-		return we.KeyR
-	case C.SDLK_s:
-		return we.KeyS
-	case C.SDLK_t:
-		return we.KeyT
-	case C.SDLK_u:
-		return we.KeyU
-	case C.SDLK_v:
-		return we.KeyV
-	case C.SDLK_w:
-		return we.KeyW
-	case C.SDLK_x:
-		return we.KeyX
-	case C.SDLK_y:
-		return we.KeyY
-	case C.SDLK_z:
-		return we.KeyZ
-	case C.SDLK_LEFTBRACKET:
-		return we.KeyLeftBracket
-	case C.SDLK_BACKSLASH:
-		return we.KeyBackslash
-	case C.SDLK_RIGHTBRACKET:
-		return we.KeyRightBracket
-	case C.SDLK_BACKQUOTE:
-		return we.KeyGraveAccent
+	C.SDLK_SPACE:        we.KeySpace,
+	C.SDLK_QUOTE:        we.KeyApostrophe,
+	C.SDLK_COMMA:        we.KeyComma,
+	C.SDLK_MINUS:        we.KeyMinus,
+	C.SDLK_PERIOD:       we.KeyPeriod,
+	C.SDLK_SLASH:        we.KeySlash,
+	C.SDLK_0:            we.Key0,
+	C.SDLK_1:            we.Key1,
+	C.SDLK_2:            we.Key2,
+	C.SDLK_3:            we.Key3,
+	C.SDLK_4:            we.Key4,
+	C.SDLK_5:            we.Key5,
+	C.SDLK_6:            we.Key6,
+	C.SDLK_7:            we.Key7,
+	C.SDLK_8:            we.Key8,
+	C.SDLK_9:            we.Key9,
+	C.SDLK_SEMICOLON:    we.KeySemicolon,
+	C.SDLK_EQUALS:       we.KeyEqual,
+	C.SDLK_a:            we.KeyA,
+	C.SDLK_b:            we.KeyB,
+	C.SDLK_c:            we.KeyC,
+	C.SDLK_d:            we.KeyD,
+	C.SDLK_e:            we.KeyE,
+	C.SDLK_f:            we.KeyF,
+	C.SDLK_g:            we.KeyG,
+	C.SDLK_h:            we.KeyH,
+	C.SDLK_i:            we.KeyI,
+	C.SDLK_j:            we.KeyJ,
+	C.SDLK_k:            we.KeyK,
+	C.SDLK_l:            we.KeyL,
+	C.SDLK_m:            we.KeyM,
+	C.SDLK_n:            we.KeyN,
+	C.SDLK_o:            we.KeyO,
+	C.SDLK_p:            we.KeyP,
+	C.SDLK_q:            we.KeyQ,
+	C.SDLK_r:            we.KeyR,
+	C.SDLK_s:            we.KeyS,
+	C.SDLK_t:            we.KeyT,
+	C.SDLK_u:            we.KeyU,
+	C.SDLK_v:            we.KeyV,
+	C.SDLK_w:            we.KeyW,
+	C.SDLK_x:            we.KeyX,
+	C.SDLK_y:            we.KeyY,
+	C.SDLK_z:            we.KeyZ,
+	C.SDLK_LEFTBRACKET:  we.KeyLeftBracket,
+	C.SDLK_BACKSLASH:    we.KeyBackslash,
+	C.SDLK_RIGHTBRACKET: we.KeyRightBracket,
+	C.SDLK_BACKQUOTE:    we.KeyGraveAccent,
 
 	// Function keys.
-	case C.SDLK_ESCAPE:
-		return we.KeyEscape
-	case C.SDLK_RETURN:
-		return we.KeyEnter
-	case C.SDLK_TAB:
-		return we.KeyTab
-	case C.SDLK_BACKSPACE:
-		return we.KeyBackspace
-	case C.SDLK_INSERT:
-		return we.KeyInsert
-	case C.SDLK_DELETE:
-		return we.KeyDelete
-	case C.SDLK_RIGHT:
-		return we.KeyRight
-	case C.SDLK_LEFT:
-		return we.KeyLeft
-	case C.SDLK_DOWN:
-		return we.KeyDown
-	case C.SDLK_UP:
-		return we.KeyUp
-	case C.SDLK_PAGEUP:
-		return we.KeyPageUp
-	case C.SDLK_PAGEDOWN:
-		return we.KeyPageDown
-	case C.SDLK_HOME:
-		return we.KeyHome
-	case C.SDLK_END:
-		return we.KeyEnd
-	case C.SDLK_CAPSLOCK:
-		return we.KeyCapsLock
-	case C.SDLK_SCROLLLOCK:
-		return we.KeyScrollLock
-	case C.SDLK_NUMLOCKCLEAR:
-		return we.KeyNumLock
-	case C.SDLK_PRINTSCREEN:
-		return we.KeyPrintScreen
-	case C.SDLK_PAUSE:
-		return we.KeyPause
-	case C.SDLK_F1:
-		return we.KeyF1
-	case C.SDLK_F2:
-		return we.KeyF2
-	case C.SDLK_F3:
-		return we.KeyF3
-	case C.SDLK_F4:
-		return we.KeyF4
-	case C.SDLK_F5:
-		return we.KeyF5
-	case C.SDLK_F6:
-		return we.KeyF6
-	case C.SDLK_F7:
-		return we.KeyF7
-	case C.SDLK_F8:
-		return we.KeyF8
-	case C.SDLK_F9:
-		return we.KeyF9
-	case C.SDLK_F10:
-		return we.KeyF10
-	case C.SDLK_F11:
-		return we.KeyF11
-	case C.SDLK_F12:
-		return we.KeyF12
-	case C.SDLK_F13:
-		return we.KeyF13
-	case C.SDLK_F14:
-		return we.KeyF14
-	case C.SDLK_F15:
-		return we.KeyF15
-	case C.SDLK_F16:
-		return we.KeyF16
-	case C.SDLK_F17:
-		return we.KeyF17
-	case C.SDLK_F18:
-		return we.KeyF18
-	case C.SDLK_F19:
-		return we.KeyF19
-	case C.SDLK_F20:
-		return we.KeyF20
-	case C.SDLK_F21:
-		return we.KeyF21
-	case C.SDLK_F22:
-		return we.KeyF22
-	case C.SDLK_F23:
-		return we.KeyF23
-	case C.SDLK_F24:
-		return we.KeyF24
-	case C.SDLK_KP_0:
-		return we.KeyKp0
-	case C.SDLK_KP_1:
-		return we.KeyKp1
-	case C.SDLK_KP_2:
-		return we.KeyKp2
-	case C.SDLK_KP_3:
-		return we.KeyKp3
-	case C.SDLK_KP_4:
-		return we.KeyKp4
-	case C.SDLK_KP_5:
-		return we.KeyKp5
-	case C.SDLK_KP_6:
-		return we.KeyKp6
-	case C.SDLK_KP_7:
-		return we.KeyKp7
-	case C.SDLK_KP_8:
-		return we.KeyKp8
-	case C.SDLK_KP_9:
-		return we.KeyKp9
-	case C.SDLK_KP_PERIOD:
-		return we.KeyKpDecimal
-	case C.SDLK_KP_DIVIDE:
-		return we.KeyKpDivide
-	case C.SDLK_KP_MULTIPLY:
-		return we.KeyKpMultiply
-	case C.SDLK_KP_MINUS:
-		return we.KeyKpSubtract
-	case C.SDLK_KP_PLUS:
-		return we.KeyKpAdd
-	case C.SDLK_KP_ENTER:
-		return we.KeyKpEnter
-	case C.SDLK_KP_EQUALS:
-		return we.KeyKpEqual
-	case C.SDLK_LSHIFT:
-		return we.KeyLeftShift
-	case C.SDLK_LCTRL:
-		return we.KeyLeftControl
-	case C.SDLK_LALT:
-		return we.KeyLeftAlt
-	case C.SDLK_LGUI:
-		return we.KeyLeftSuper
-	case C.SDLK_RSHIFT:
-		return we.KeyRightShift
-	case C.SDLK_RCTRL:
-		return we.KeyRightControl
-	case C.SDLK_RALT:
-		return we.KeyRightAlt
-	case C.SDLK_RGUI:
-		return we.KeyRightSuper
-	case C.SDLK_MENU:
-		return we.KeyMenu
-	}
+	C.SDLK_ESCAPE:       we.KeyEscape,
+	C.SDLK_RETURN:       we.KeyEnter,
+	C.SDLK_TAB:          we.KeyTab,
+	C.SDLK_BACKSPACE:    we.KeyBackspace,
+	C.SDLK_INSERT:       we.KeyInsert,
+	C.SDLK_DELETE:       we.KeyDelete,
+	C.SDLK_RIGHT:        we.KeyRight,
+	C.SDLK_LEFT:         we.KeyLeft,
+	C.SDLK_DOWN:         we.KeyDown,
+	C.SDLK_UP:           we.KeyUp,
+	C.SDLK_PAGEUP:       we.KeyPageUp,
+	C.SDLK_PAGEDOWN:     we.KeyPageDown,
+	C.SDLK_HOME:         we.KeyHome,
+	C.SDLK_END:          we.KeyEnd,
+	C.SDLK_CAPSLOCK:     we.KeyCapsLock,
+	C.SDLK_SCROLLLOCK:   we.KeyScrollLock,
+	C.SDLK_NUMLOCKCLEAR: we.KeyNumLock,
+	C.SDLK_PRINTSCREEN:  we.KeyPrintScreen,
+	C.SDLK_PAUSE:        we.KeyPause,
+	C.SDLK_F1:           we.KeyF1,
+	C.SDLK_F2:           we.KeyF2,
+	C.SDLK_F3:           we.KeyF3,
+	C.SDLK_F4:           we.KeyF4,
+	C.SDLK_F5:           we.KeyF5,
+	C.SDLK_F6:           we.KeyF6,
+	C.SDLK_F7:           we.KeyF7,
+	C.SDLK_F8:           we.KeyF8,
+	C.SDLK_F9:           we.KeyF9,
+	C.SDLK_F10:          we.KeyF10,
+	C.SDLK_F11:          we.KeyF11,
+	C.SDLK_F12:          we.KeyF12,
+	C.SDLK_F13:          we.KeyF13,
+	C.SDLK_F14:          we.KeyF14,
+	C.SDLK_F15:          we.KeyF15,
+	C.SDLK_F16:          we.KeyF16,
+	C.SDLK_F17:          we.KeyF17,
+	C.SDLK_F18:          we.KeyF18,
+	C.SDLK_F19:          we.KeyF19,
+	C.SDLK_F20:          we.KeyF20,
+	C.SDLK_F21:          we.KeyF21,
+	C.SDLK_F22:          we.KeyF22,
+	C.SDLK_F23:          we.KeyF23,
+	C.SDLK_F24:          we.KeyF24,
+	C.SDLK_KP_0:         we.KeyKp0,
+	C.SDLK_KP_1:         we.KeyKp1,
+	C.SDLK_KP_2:         we.KeyKp2,
+	C.SDLK_KP_3:         we.KeyKp3,
+	C.SDLK_KP_4:         we.KeyKp4,
+	C.SDLK_KP_5:         we.KeyKp5,
+	C.SDLK_KP_6:         we.KeyKp6,
+	C.SDLK_KP_7:         we.KeyKp7,
+	C.SDLK_KP_8:         we.KeyKp8,
+	C.SDLK_KP_9:         we.KeyKp9,
+	C.SDLK_KP_PERIOD:    we.KeyKpDecimal,
+	C.SDLK_KP_DIVIDE:    we.KeyKpDivide,
+	C.SDLK_KP_MULTIPLY:  we.KeyKpMultiply,
+	C.SDLK_KP_MINUS:     we.KeyKpSubtract,
+	C.SDLK_KP_PLUS:      we.KeyKpAdd,
+	C.SDLK_KP_ENTER:     we.KeyKpEnter,
+	C.SDLK_KP_EQUALS:    we.KeyKpEqual,
+	C.SDLK_LSHIFT:       we.KeyLeftShift,
+	C.SDLK_LCTRL:        we.KeyLeftControl,
+	C.SDLK_LALT:         we.KeyLeftAlt,
+	C.SDLK_LGUI:         we.KeyLeftSuper,
+	C.SDLK_RSHIFT:       we.KeyRightShift,
+	C.SDLK_RCTRL:        we.KeyRightControl,
+	C.SDLK_RALT:         we.KeyRightAlt,
+	C.SDLK_RGUI:         we.KeyRightSuper,
+	C.SDLK_MENU:         we.KeyMenu,
+}
+
+// keycodes maps from we.Key to the corresponding SDL keycodes.
+var keycodes = make(map[we.Key]C.SDL_Keycode)
 
-	// Unknown key.
-	return 0
+func init() {
+	for keycode, key := range keys {
+		keycodes[key] = keycode
+	}
 }
@@ -4,9 +4,143 @@ package win
 import "C"
 
 import (
+	"image"
+
 	"github.com/mewmew/we"
 )
 
+// MouseRelMove is a mouse event which is triggered when the mouse is moved in
+// relative mouse mode. It carries the relative motion of the mouse, as the
+// cursor is hidden and locked in place while in relative mouse mode.
+type MouseRelMove struct {
+	// The relative motion of the mouse.
+	Rel image.Point
+	// The state of the mouse buttons.
+	Buttons ButtonState
+	// The active keyboard modifiers.
+	Mod we.Mod
+}
+
+// SetRelativeMouseMode enables or disables relative mouse mode. While enabled
+// the cursor is hidden, the mouse is confined to the window and mouse motion is
+// reported through MouseRelMove events, even when the cursor would have reached
+// the edge of the screen.
+//
+// Mouse motion which is pending when the mode changes is reported according to
+// the mode at the time of the motion.
+func SetRelativeMouseMode(enabled bool) (err error) {
+	if enabled != RelativeMouseMode() {
+		// Translate pending mouse motion before the mode changes, as PollEvent
+		// would otherwise report it according to the new mode.
+		queueEvents()
+	}
+	if C.SDL_SetRelativeMouseMode(cBool(enabled)) != 0 {
+		return getError()
+	}
+	return nil
+}
+
+// RelativeMouseMode returns true if relative mouse mode is enabled, and false
+// otherwise.
+func RelativeMouseMode() bool {
+	return C.SDL_GetRelativeMouseMode() == C.SDL_TRUE
+}
+
+// WarpMouse moves the mouse cursor to the provided point of the window.
+func WarpMouse(pt image.Point) {
+	C.SDL_WarpMouseInWindow(w, C.int(pt.X), C.int(pt.Y))
+}
+
+// SetGrab grabs or releases the mouse. While grabbed the mouse is confined to
+// the window.
+func SetGrab(grabbed bool) {
+	C.SDL_SetWindowGrab(w, cBool(grabbed))
+}
+
+// Grabbed returns true if the mouse is grabbed by the window, and false
+// otherwise.
+func Grabbed() bool {
+	return C.SDL_GetWindowGrab(w) == C.SDL_TRUE
+}
+
+// CaptureMouse enables or disables mouse capture. While captured the window
+// receives mouse events even when the cursor is outside of the window, e.g.
+// to track a drag operation which leaves the window.
+func CaptureMouse(enabled bool) (err error) {
+	if C.SDL_CaptureMouse(cBool(enabled)) != 0 {
+		return getError()
+	}
+	return nil
+}
+
+// ButtonState is a snapshot of the mouse button state, as returned by
+// MouseState and GlobalMouseState.
+type ButtonState struct {
+	// SDL mouse button state bitfield.
+	state C.Uint32
+}
+
+// MouseState returns the position of the mouse cursor, relative to the window,
+// and the state of the mouse buttons. It is updated as events are processed,
+// and therefore reflects the state of the mouse as of the last call to
+// PollEvent.
+func MouseState() (pt image.Point, buttons ButtonState) {
+	var x, y C.int
+	buttons.state = C.SDL_GetMouseState(&x, &y)
+	return image.Pt(int(x), int(y)), buttons
+}
+
+// GlobalMouseState returns the position of the mouse cursor, relative to the
+// desktop, and the state of the mouse buttons. Contrary to MouseState it
+// queries the operating system directly, and works even when the cursor is
+// outside of the window.
+func GlobalMouseState() (pt image.Point, buttons ButtonState) {
+	var x, y C.int
+	buttons.state = C.SDL_GetGlobalMouseState(&x, &y)
+	return image.Pt(int(x), int(y)), buttons
+}
+
+// IsDown returns true if the provided mouse button is held down, and false
+// otherwise.
+func (buttons ButtonState) IsDown(button we.Button) bool {
+	index := cButton(button)
+	if index < 1 || index > 32 {
+		return false
+	}
+	mask := C.Uint32(1 << (index - 1))
+	return buttons.state&mask != 0
+}
+
+// Buttons returns the mouse buttons which are held down.
+func (buttons ButtonState) Buttons() (pressed []we.Button) {
+	for index := uint(1); index <= 32; index++ {
+		mask := C.Uint32(1 << (index - 1))
+		if buttons.state&mask != 0 {
+			pressed = append(pressed, goButton(C.Uint8(index)))
+		}
+	}
+	return pressed
+}
+
+// cButton returns the corresponding SDL mouse button index for the provided
+// we.Button.
+func cButton(button we.Button) (index int) {
+	switch button {
+	case we.ButtonLeft:
+		return C.SDL_BUTTON_LEFT
+	case we.ButtonRight:
+		return C.SDL_BUTTON_RIGHT
+	case we.ButtonMiddle:
+		return C.SDL_BUTTON_MIDDLE
+	case we.Button4:
+		return C.SDL_BUTTON_X1
+	case we.Button5:
+		return C.SDL_BUTTON_X2
+	}
+	// All buttons above we.Button5 are mapped to unknown SDL mouse buttons.
+	return C.SDL_BUTTON_X2 + int(button-we.Button5)
+}
+
 // goButton returns the corresponding we.Button for the provided SDL mouse
 // button index.
 func goButton(index C.Uint8) (button we.Button) {
@@ -0,0 +1,58 @@
+package win
+
+import (
+	"image"
+	"reflect"
+	"testing"
+
+	"github.com/mewmew/we"
+)
+
+func TestQueueEvents(t *testing.T) {
+	openHeadless(t, 64, 48)
+	if err := pushMouseMotion(image.Pt(10, 20), image.Pt(2, -3)); err != nil {
+		t.Fatal(err)
+	}
+	queueEvents()
+	if err := pushMouseMotion(image.Pt(11, 22), image.Pt(1, 2)); err != nil {
+		t.Fatal(err)
+	}
+	want := []interface{}{
+		we.MouseMove{Point: image.Pt(10, 20), From: image.Pt(8, 23)},
+		we.MouseMove{Point: image.Pt(11, 22), From: image.Pt(10, 20)},
+		nil,
+	}
+	for i, w := range want {
+		if event := PollEvent(); !reflect.DeepEqual(event, w) {
+			t.Errorf("event %d: mismatch; expected %#v, got %#v", i, w, event)
+		}
+	}
+}
+
+func TestRelativeMouseModePending(t *testing.T) {
+	openHeadless(t, 64, 48)
+
+	// Motion which is pending when relative mouse mode is enabled must be
+	// reported as absolute motion, and vice versa.
+	if err := pushMouseMotion(image.Pt(10, 20), image.Pt(2, -3)); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetRelativeMouseMode(true); err != nil {
+		t.Skipf("relative mouse mode not supported: %v", err)
+	}
+	if err := pushMouseMotion(image.Pt(10, 20), image.Pt(4, 5)); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetRelativeMouseMode(false); err != nil {
+		t.Fatal(err)
+	}
+	want := []interface{}{
+		we.MouseMove{Point: image.Pt(10, 20), From: image.Pt(8, 23)},
+		MouseRelMove{Rel: image.Pt(4, 5)},
+	}
+	for i, w := range want {
+		if event := PollEvent(); !reflect.DeepEqual(event, w) {
+			t.Errorf("event %d: mismatch; expected %#v, got %#v", i, w, event)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package win
+
+// #cgo pkg-config: sdl2
+// #include <SDL2/SDL.h>
+import "C"
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Optimize returns a copy of the image converted to the pixel format of the
+// window, which avoids converting its pixels each time it is drawn onto the
+// window. Images with an alpha channel are converted to a pixel format with
+// the same color layout as the window and an additional alpha channel. The
+// blend mode, and alpha and color modulation of the image are preserved. The
+// color key of images with an alpha channel is converted to transparent
+// pixels, and preserved otherwise.
+//
+// The window must be open. Images should be optimized once, e.g. at load time,
+// as the conversion is comparatively slow.
+//
+// Note: The Free method of the returned image should be called when finished
+// using it.
+func (img *Image) Optimize() (optimized *Image, err error) {
+	if w == nil {
+		return nil, errors.New("win.Image.Optimize: window not open")
+	}
+	format := C.SDL_GetWindowPixelFormat(w)
+	if format == C.SDL_PIXELFORMAT_UNKNOWN {
+		return nil, getError()
+	}
+	if img.s.format.Amask != 0 {
+		format, err = alphaFormat(format)
+		if err != nil {
+			return nil, err
+		}
+	}
+	s := C.SDL_ConvertSurfaceFormat(img.s, format, 0)
+	if s == nil {
+		return nil, getError()
+	}
+	optimized = &Image{
+		Width:  int(s.w),
+		Height: int(s.h),
+		s:      s,
+	}
+	if err := optimized.inheritConverted(img); err != nil {
+		optimized.Free()
+		return nil, err
+	}
+	return optimized, nil
+}
+
+// alphaFormat returns a 32-bit pixel format with an alpha channel and the same
+// color layout as the provided pixel format, if possible. It falls back to
+// ARGB8888, which SDL blits efficiently, for other pixel formats.
+func alphaFormat(format C.Uint32) (C.Uint32, error) {
+	var bpp C.int
+	var r, g, b, a C.Uint32
+	if C.SDL_PixelFormatEnumToMasks(format, &bpp, &r, &g, &b, &a) != C.SDL_TRUE {
+		return 0, getError()
+	}
+	if a != 0 {
+		return format, nil
+	}
+	if bpp == 24 {
+		// Use the remaining bits of a 32-bit pixel as alpha channel, e.g.
+		// ARGB8888 for RGB888 and RGB24.
+		a = ^(r | g | b)
+		if alpha := C.SDL_MasksToPixelFormatEnum(32, r, g, b, a); alpha != C.SDL_PIXELFORMAT_UNKNOWN {
+			return alpha, nil
+		}
+	}
+	return C.SDL_PIXELFORMAT_ARGB8888, nil
+}
+
+// SetRLE enables or disables run-length encoding (RLE) acceleration of the
+// image. RLE speeds up drawing of images with a color key or large fully
+// transparent areas considerably, such as sprites, at the expense of slower
+// access to the pixels of the image. It should therefore only be enabled for
+// images which are drawn often but rarely modified.
+//
+// SDL frees the pixel buffer of the image once it is RLE encoded, which would
+// leave sub-images pointing at freed memory. RLE acceleration can therefore
+// not be enabled while sub-images of the image are in use, such as the sprites
+// of a SpriteSheet, and sub-images of RLE accelerated images can not be
+// created; see Image.SubImage.
+func (img *Image) SetRLE(enable bool) (err error) {
+	var flag C.int
+	if enable {
+		if n := viewCounts[img.s]; n > 0 {
+			return fmt.Errorf("win.Image.SetRLE: unable to enable RLE acceleration of image with %d sub-images in use", n)
+		}
+		flag = 1
+	}
+	if C.SDL_SetSurfaceRLE(img.s, flag) != 0 {
+		return getError()
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package win
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// spriteImage returns a sprite with a filled circle on a transparent
+// background.
+func spriteImage(size int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, size, size))
+	r := size / 2
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			if dx, dy := x-r, y-r; dx*dx+dy*dy < r*r {
+				img.SetNRGBA(x, y, color.NRGBA{R: uint8(x), G: uint8(y), B: 0x80, A: 0xFF})
+			}
+		}
+	}
+	return img
+}
+
+func TestSetRLESubImage(t *testing.T) {
+	img := newTestImage(t, spriteImage(16))
+	sub, err := img.SubImage(image.Rect(4, 4, 8, 8))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Views of views count towards the image which owns the pixels.
+	subsub, err := sub.SubImage(image.Rect(1, 1, 2, 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sub.Free()
+	if err := img.SetRLE(true); err == nil {
+		t.Error("RLE acceleration with sub-image in use; expected error, got nil")
+	}
+	subsub.Free()
+	if err := img.SetRLE(true); err != nil {
+		t.Errorf("RLE acceleration without sub-images in use; expected nil, got %v", err)
+	}
+	if _, err := img.SubImage(image.Rect(4, 4, 8, 8)); err == nil {
+		t.Error("sub-image of RLE accelerated image; expected error, got nil")
+	}
+}
+
+// benchmarkDraw benchmarks drawing the provided sprite onto the window.
+func benchmarkDraw(b *testing.B, prepare func(img *Image) (*Image, error)) {
+	openHeadless(b, 640, 480)
+	img := newTestImage(b, spriteImage(128))
+	sprite, err := prepare(img)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := Draw(image.Pt(i%512, i%352), sprite); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDraw(b *testing.B) {
+	benchmarkDraw(b, func(img *Image) (*Image, error) {
+		return img, nil
+	})
+}
+
+func BenchmarkDrawOptimized(b *testing.B) {
+	benchmarkDraw(b, func(img *Image) (*Image, error) {
+		optimized, err := img.Optimize()
+		if err != nil {
+			return nil, err
+		}
+		b.Cleanup(optimized.Free)
+		return optimized, nil
+	})
+}
+
+func BenchmarkDrawRLE(b *testing.B) {
+	benchmarkDraw(b, func(img *Image) (*Image, error) {
+		optimized, err := img.Optimize()
+		if err != nil {
+			return nil, err
+		}
+		b.Cleanup(optimized.Free)
+		if err := optimized.SetRLE(true); err != nil {
+			return nil, err
+		}
+		return optimized, nil
+	})
+}
+
+func TestOptimizeBlendMode(t *testing.T) {
+	openHeadless(t, 16, 16)
+	src := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	src.SetNRGBA(0, 0, color.NRGBA{R: 0x40, G: 0x80, B: 0x20, A: 0x80})
+	src.SetNRGBA(1, 0, color.NRGBA{R: 0x10, G: 0x20, B: 0x30, A: 0xFF})
+	for _, mode := range []BlendMode{BlendAdd, BlendNone} {
+		img := newTestImage(t, src)
+		if err := img.SetBlendMode(mode); err != nil {
+			t.Fatal(err)
+		}
+		optimized, err := img.Optimize()
+		if err != nil {
+			t.Fatal(err)
+		}
+		// The optimized image must draw like the original image.
+		var results []*image.NRGBA
+		for _, sprite := range []*Image{img, optimized} {
+			dst := newTestImage(t, image.NewNRGBA(image.Rect(0, 0, 2, 1)))
+			if err := dst.Fill(color.NRGBA{R: 0x30, G: 0x30, B: 0x30, A: 0xFF}); err != nil {
+				t.Fatal(err)
+			}
+			if err := dst.Draw(image.ZP, sprite); err != nil {
+				t.Fatal(err)
+			}
+			results = append(results, toNRGBA(t, dst))
+		}
+		optimized.Free()
+		for x := 0; x < 2; x++ {
+			want, got := results[0].NRGBAAt(x, 0), results[1].NRGBAAt(x, 0)
+			if colorDiff(want, got) > 1 {
+				t.Errorf("blend mode %d: pixel (%d, 0) mismatch; expected %v, got %v", mode, x, want, got)
+			}
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package win
+
+import (
+	"image"
+	"math"
+)
+
+// A point is a point with floating-point coordinates.
+type point struct {
+	x, y float64
+}
+
+// add returns the vector p+q.
+func (p point) add(q point) point {
+	return point{p.x + q.x, p.y + q.y}
+}
+
+// sub returns the vector p-q.
+func (p point) sub(q point) point {
+	return point{p.x - q.x, p.y - q.y}
+}
+
+// mul returns the vector p*k.
+func (p point) mul(k float64) point {
+	return point{p.x * k, p.y * k}
+}
+
+// len returns the length of the vector p.
+func (p point) len() float64 {
+	return math.Hypot(p.x, p.y)
+}
+
+// An frect is a rectangle with floating-point coordinates.
+type frect struct {
+	min, max point
+}
+
+// rect returns the floating-point rectangle covering the pixels of r.
+func rect(r image.Rectangle) frect {
+	return frect{
+		min: point{float64(r.Min.X), float64(r.Min.Y)},
+		max: point{float64(r.Max.X), float64(r.Max.Y)},
+	}
+}
+
+// centers returns the centers of the pixels at the provided points.
+func centers(pts []image.Point) []point {
+	ps := make([]point, len(pts))
+	for i, pt := range pts {
+		ps[i] = point{float64(pt.X) + 0.5, float64(pt.Y) + 0.5}
+	}
+	return ps
+}
+
+// The polygons below are oriented clockwise on screen, i.e. with positive
+// signed area in image coordinates, unless reversed. Overlapping polygons of
+// the same orientation add up when filled, while polygons of opposite
+// orientation cancel out, e.g. to cut a hole.
+
+// ellipse returns a polygon which approximates the axis-aligned ellipse with
+// the provided center and horizontal and vertical radii.
+func ellipse(c point, rx, ry float64, reverse bool) []point {
+	// Use line segments of about one pixel in length.
+	n := int(math.Ceil(math.Pi * (rx + ry)))
+	if n < 8 {
+		n = 8
+	}
+	poly := make([]point, n)
+	for i := range poly {
+		t := 2 * math.Pi * float64(i) / float64(n)
+		if reverse {
+			t = -t
+		}
+		sin, cos := math.Sincos(t)
+		poly[i] = point{c.x + rx*cos, c.y + ry*sin}
+	}
+	return poly
+}
+
+// roundedRect returns a polygon which approximates the rectangle r with
+// corners rounded by the provided radius.
+func roundedRect(r frect, radius float64, reverse bool) []point {
+	radius = math.Min(radius, math.Min(r.max.x-r.min.x, r.max.y-r.min.y)/2)
+	// Centers of the corner arcs, in clockwise order starting with the bottom
+	// right corner.
+	cs := []point{
+		{r.max.x - radius, r.max.y - radius},
+		{r.min.x + radius, r.max.y - radius},
+		{r.min.x + radius, r.min.y + radius},
+		{r.max.x - radius, r.min.y + radius},
+	}
+	n := int(math.Ceil(math.Pi * radius / 2))
+	var poly []point
+	for i, c := range cs {
+		if n == 0 {
+			poly = append(poly, c)
+			continue
+		}
+		for j := 0; j <= n; j++ {
+			t := math.Pi / 2 * (float64(i) + float64(j)/float64(n))
+			sin, cos := math.Sincos(t)
+			poly = append(poly, point{c.x + radius*cos, c.y + radius*sin})
+		}
+	}
+	if reverse {
+		for i, j := 0, len(poly)-1; i < j; i, j = i+1, j-1 {
+			poly[i], poly[j] = poly[j], poly[i]
+		}
+	}
+	return poly
+}
+
+// stroke returns polygons which cover the lines of the provided width between
+// the consecutive points of pts, and between the last and the first point if
+// closed. The lines are joined by rounded corners. The ends of open lines are
+// square.
+func stroke(pts []point, closed bool, width float64) (polys [][]point) {
+	w := width / 2
+	if w <= 0 {
+		return nil
+	}
+	// Skip repeated points, which have no direction.
+	var ps []point
+	for _, p := range pts {
+		if len(ps) == 0 || p != ps[len(ps)-1] {
+			ps = append(ps, p)
+		}
+	}
+	if closed && len(ps) > 1 && ps[0] == ps[len(ps)-1] {
+		ps = ps[:len(ps)-1]
+	}
+	if len(ps) == 1 {
+		// Draw a single point as a square.
+		p := ps[0]
+		return [][]point{{{p.x - w, p.y - w}, {p.x + w, p.y - w}, {p.x + w, p.y + w}, {p.x - w, p.y + w}}}
+	}
+
+	nsegs := len(ps) - 1
+	if closed {
+		nsegs = len(ps)
+	}
+	for i := 0; i < nsegs; i++ {
+		a, b := ps[i], ps[(i+1)%len(ps)]
+		d := b.sub(a)
+		d = d.mul(1 / d.len())
+		// The quadrilateral of the segment has negative signed area,
+		// regardless of the direction of the segment.
+		n := point{-d.y, d.x}.mul(w)
+		if !closed && i == 0 {
+			a = a.sub(d.mul(w))
+		}
+		if !closed && i == nsegs-1 {
+			b = b.add(d.mul(w))
+		}
+		polys = append(polys, []point{a.add(n), b.add(n), b.sub(n), a.sub(n)})
+	}
+	// Round the joins between segments, using circles of the same orientation
+	// as the segments.
+	for i, p := range ps {
+		if !closed && (i == 0 || i == len(ps)-1) {
+			continue
+		}
+		polys = append(polys, ellipse(p, w, w, true))
+	}
+	return polys
+}
@@ -0,0 +1,85 @@
+package win
+
+// #cgo pkg-config: sdl2
+// #include <SDL2/SDL.h>
+//
+// int pushMouseMotion(int x, int y, int xrel, int yrel) {
+//    SDL_Event e;
+//    SDL_zero(e);
+//    e.type = SDL_MOUSEMOTION;
+//    e.motion.x = x;
+//    e.motion.y = y;
+//    e.motion.xrel = xrel;
+//    e.motion.yrel = yrel;
+//    return SDL_PushEvent(&e);
+// }
+//
+// int pushTouchFinger(Uint32 type, SDL_TouchID touchID, SDL_FingerID fingerID, float x, float y, float dx, float dy, float pressure) {
+//    SDL_Event e;
+//    SDL_zero(e);
+//    e.type = type;
+//    e.tfinger.touchId = touchID;
+//    e.tfinger.fingerId = fingerID;
+//    e.tfinger.x = x;
+//    e.tfinger.y = y;
+//    e.tfinger.dx = dx;
+//    e.tfinger.dy = dy;
+//    e.tfinger.pressure = pressure;
+//    return SDL_PushEvent(&e);
+// }
+//
+// int pushMultiGesture(SDL_TouchID touchID, float x, float y, float dTheta, float dDist, Uint16 numFingers) {
+//    SDL_Event e;
+//    SDL_zero(e);
+//    e.type = SDL_MULTIGESTURE;
+//    e.mgesture.touchId = touchID;
+//    e.mgesture.x = x;
+//    e.mgesture.y = y;
+//    e.mgesture.dTheta = dTheta;
+//    e.mgesture.dDist = dDist;
+//    e.mgesture.numFingers = numFingers;
+//    return SDL_PushEvent(&e);
+// }
+import "C"
+
+import "image"
+
+// This file contains helpers which push synthetic window events onto the SDL
+// event queue, e.g. to test the translation of events which can not be
+// generated by the headless video drivers.
+
+// pushMouseMotion pushes a mouse motion event to the provided point, moved by
+// rel.
+func pushMouseMotion(pt, rel image.Point) (err error) {
+	if C.pushMouseMotion(C.int(pt.X), C.int(pt.Y), C.int(rel.X), C.int(rel.Y)) < 0 {
+		return getError()
+	}
+	return nil
+}
+
+// pushFingerDown pushes a finger down event at the provided normalized touch
+// coordinates.
+func pushFingerDown(touchID, fingerID int64, x, y, pressure float64) (err error) {
+	if C.pushTouchFinger(C.SDL_FINGERDOWN, C.SDL_TouchID(touchID), C.SDL_FingerID(fingerID), C.float(x), C.float(y), 0, 0, C.float(pressure)) < 0 {
+		return getError()
+	}
+	return nil
+}
+
+// pushFingerMove pushes a finger motion event at the provided normalized touch
+// coordinates, moved by the provided normalized relative motion.
+func pushFingerMove(touchID, fingerID int64, x, y, dx, dy, pressure float64) (err error) {
+	if C.pushTouchFinger(C.SDL_FINGERMOTION, C.SDL_TouchID(touchID), C.SDL_FingerID(fingerID), C.float(x), C.float(y), C.float(dx), C.float(dy), C.float(pressure)) < 0 {
+		return getError()
+	}
+	return nil
+}
+
+// pushMultiGesture pushes a multi-finger gesture event centered at the provided
+// normalized touch coordinates.
+func pushMultiGesture(touchID int64, x, y, rotation, pinch float64, fingers int) (err error) {
+	if C.pushMultiGesture(C.SDL_TouchID(touchID), C.float(x), C.float(y), C.float(rotation), C.float(pinch), C.Uint16(fingers)) < 0 {
+		return getError()
+	}
+	return nil
+}
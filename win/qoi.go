@@ -0,0 +1,147 @@
+package win
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"io"
+)
+
+// The QOI image format is specified at https://qoiformat.org/qoi-specification.pdf
+
+// qoiMagic is the signature of QOI image files.
+const qoiMagic = "qoif"
+
+// maxDecodePixels is the maximum number of pixels of images decoded by the QOI
+// and TGA decoders, which guards against huge allocations for corrupt headers.
+// Compressed pixel data may legitimately be much smaller than the image, so the
+// limit bounds the allocation made before the pixel data is read.
+const maxDecodePixels = 16384 * 16384
+
+// QOI chunk tags.
+const (
+	qoiOpRGB   = 0xFE
+	qoiOpRGBA  = 0xFF
+	qoiOpIndex = 0x00
+	qoiOpDiff  = 0x40
+	qoiOpLuma  = 0x80
+	qoiOpRun   = 0xC0
+	// qoiMask2 masks the 2-bit tags.
+	qoiMask2 = 0xC0
+)
+
+func init() {
+	image.RegisterFormat("qoi", qoiMagic, decodeQOI, decodeQOIConfig)
+}
+
+// qoiHeader is the header of QOI image files.
+type qoiHeader struct {
+	Magic  [4]byte
+	Width  uint32
+	Height uint32
+	// Channels is 3 for RGB and 4 for RGBA images. The decoded image is the
+	// same in both cases.
+	Channels uint8
+	// Colorspace is 0 for sRGB with linear alpha and 1 for linear images. It
+	// is informative only.
+	Colorspace uint8
+}
+
+// readQOIHeader reads and validates the header of a QOI image.
+func readQOIHeader(r io.Reader) (hdr *qoiHeader, err error) {
+	hdr = new(qoiHeader)
+	err = binary.Read(r, binary.BigEndian, hdr)
+	if err != nil {
+		return nil, err
+	}
+	if string(hdr.Magic[:]) != qoiMagic {
+		return nil, errors.New("win.readQOIHeader: invalid QOI signature")
+	}
+	if hdr.Width == 0 || hdr.Height == 0 || uint64(hdr.Width)*uint64(hdr.Height) > maxDecodePixels {
+		return nil, errors.New("win.readQOIHeader: invalid QOI image dimensions")
+	}
+	return hdr, nil
+}
+
+// decodeQOIConfig returns the color model and dimensions of the QOI image read
+// from r.
+func decodeQOIConfig(r io.Reader) (image.Config, error) {
+	hdr, err := readQOIHeader(r)
+	if err != nil {
+		return image.Config{}, err
+	}
+	config := image.Config{
+		ColorModel: color.NRGBAModel,
+		Width:      int(hdr.Width),
+		Height:     int(hdr.Height),
+	}
+	return config, nil
+}
+
+// decodeQOI decodes the QOI image read from r.
+func decodeQOI(r io.Reader) (image.Image, error) {
+	br := bufio.NewReader(r)
+	hdr, err := readQOIHeader(br)
+	if err != nil {
+		return nil, err
+	}
+	img := image.NewNRGBA(image.Rect(0, 0, int(hdr.Width), int(hdr.Height)))
+
+	// index holds previously seen pixels, indexed by the hash of their colors.
+	var index [64][4]uint8
+	px := [4]uint8{0, 0, 0, 0xFF}
+	var buf [4]byte
+	for i := 0; i < len(img.Pix); {
+		tag, err := br.ReadByte()
+		if err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		run := 1
+		switch {
+		case tag == qoiOpRGB:
+			if _, err := io.ReadFull(br, buf[:3]); err != nil {
+				return nil, unexpectedEOF(err)
+			}
+			copy(px[:3], buf[:3])
+		case tag == qoiOpRGBA:
+			if _, err := io.ReadFull(br, buf[:4]); err != nil {
+				return nil, unexpectedEOF(err)
+			}
+			copy(px[:], buf[:4])
+		case tag&qoiMask2 == qoiOpIndex:
+			px = index[tag]
+		case tag&qoiMask2 == qoiOpDiff:
+			px[0] += (tag>>4)&0x03 - 2
+			px[1] += (tag>>2)&0x03 - 2
+			px[2] += tag&0x03 - 2
+		case tag&qoiMask2 == qoiOpLuma:
+			b, err := br.ReadByte()
+			if err != nil {
+				return nil, unexpectedEOF(err)
+			}
+			dg := tag&0x3F - 32
+			px[0] += dg + b>>4 - 8
+			px[1] += dg
+			px[2] += dg + b&0x0F - 8
+		case tag&qoiMask2 == qoiOpRun:
+			run = int(tag&0x3F) + 1
+		}
+		index[(int(px[0])*3+int(px[1])*5+int(px[2])*7+int(px[3])*11)%64] = px
+		for ; run > 0 && i < len(img.Pix); run-- {
+			copy(img.Pix[i:i+4], px[:])
+			i += 4
+		}
+	}
+	return img, nil
+}
+
+// unexpectedEOF returns io.ErrUnexpectedEOF if err is io.EOF, and err
+// otherwise.
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
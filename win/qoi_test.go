@@ -0,0 +1,36 @@
+package win
+
+import (
+	"image"
+	"image/color"
+	"reflect"
+	"testing"
+)
+
+func TestDecodeQOI(t *testing.T) {
+	// The QOI test image, generated by an independent encoder, holds a 10x8
+	// image encoded using every chunk type: RGB, DIFF, LUMA, RUN, RGBA, INDEX,
+	// and a RUN which exceeds the maximum run length of a chunk.
+	pix := []color.NRGBA{
+		{R: 10, G: 20, B: 30, A: 255},   // RGB
+		{R: 11, G: 19, B: 31, A: 255},   // DIFF
+		{R: 20, G: 25, B: 30, A: 255},   // LUMA
+		{R: 20, G: 25, B: 30, A: 255},   // RUN
+		{R: 20, G: 25, B: 30, A: 255},   // RUN
+		{R: 10, G: 20, B: 30, A: 128},   // RGBA
+		{R: 10, G: 20, B: 30, A: 255},   // INDEX
+		{R: 200, G: 100, B: 50, A: 255}, // RGB
+	}
+	for len(pix) < 10*8 {
+		// RUN of 62 pixels followed by a RUN of 10 pixels.
+		pix = append(pix, pix[7])
+	}
+	want := image.NewNRGBA(image.Rect(0, 0, 10, 8))
+	for i, c := range pix {
+		want.SetNRGBA(i%10, i/10, c)
+	}
+	img := decodeFile(t, "testdata/ops.qoi", "qoi")
+	if !reflect.DeepEqual(img, want) {
+		t.Errorf("image mismatch; expected %v, got %v", want.Pix, img.(*image.NRGBA).Pix)
+	}
+}
@@ -0,0 +1,596 @@
+package win
+
+// #include <SDL2/SDL.h>
+import "C"
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"math"
+	"time"
+
+	"github.com/mewmew/we"
+)
+
+// A TimedEvent is an event together with the time at which it was received.
+type TimedEvent struct {
+	// The time at which the event was received, measured from the
+	// initialization of the SDL library.
+	Time time.Duration
+	// The event, which is of any type returned by PollEvent.
+	Event interface{}
+}
+
+// recorder is the active event recorder, or nil if no recording is in
+// progress.
+var recorder *eventWriter
+
+// StartRecording starts to record every event returned by PollEvent. The events
+// are encoded in a compact binary format and written to w, from which they may
+// be read back using ReadEvents.
+//
+// Note: The StopRecording function must be called when finished recording.
+func StartRecording(w io.Writer) (err error) {
+	if recorder != nil {
+		return errors.New("win.StartRecording: a recording is already in progress")
+	}
+	rec := newEventWriter(w)
+	err = rec.writeHeader()
+	if err != nil {
+		return err
+	}
+	recorder = rec
+	return nil
+}
+
+// StopRecording stops the active event recording and flushes any buffered
+// events. It returns the first error encountered while recording, if any.
+func StopRecording() (err error) {
+	if recorder == nil {
+		return errors.New("win.StopRecording: no recording in progress")
+	}
+	rec := recorder
+	recorder = nil
+	if rec.err != nil {
+		return rec.err
+	}
+	return rec.w.Flush()
+}
+
+// record records the provided event if a recording is in progress.
+func record(t time.Duration, event interface{}) {
+	if recorder == nil {
+		return
+	}
+	recorder.writeEvent(TimedEvent{Time: t, Event: event})
+}
+
+// ReadEvents reads and decodes an event recording, as produced by
+// StartRecording, from r.
+func ReadEvents(r io.Reader) (events []TimedEvent, err error) {
+	dec := &eventReader{r: bufio.NewReader(r)}
+	err = dec.readHeader()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		event, err := dec.readEvent()
+		if err == io.EOF {
+			return events, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+}
+
+// The event recording format starts with a header containing the magic
+// string followed by a version byte. Each event is stored as the uvarint
+// encoded time in milliseconds since the previous event, followed by a kind
+// byte, the varint encoded fields of the event and its length-prefixed strings.
+const (
+	// recordMagic is the magic string identifying an event recording.
+	recordMagic = "WEVT"
+	// recordVersion is the version of the event recording format. Version 2
+	// added the scancode to keyboard events, and version 3 added the events of
+	// registered event codecs. Version 4 stored the relative motion of finger
+	// events as floating-point numbers.
+	recordVersion = 4
+	// maxRecordString is the maximum length in bytes of event strings, which
+	// guards against huge allocations when reading corrupt recordings.
+	maxRecordString = 1 << 20
+	// maxRecordDrop is the maximum number of dropped files and texts of drop
+	// events.
+	maxRecordDrop = 1 << 16
+	// maxCodecFields is the maximum number of fields of events encoded by
+	// registered event codecs.
+	maxCodecFields = 64
+)
+
+// Event kinds of the event recording format.
+const (
+	kindClose byte = iota + 1
+	kindResize
+	kindMouseEnter
+	kindKeyPress
+	kindKeyRepeat
+	kindKeyRelease
+	kindKeyRune
+	kindMouseMove
+	kindMouseDrag
+	kindMousePress
+	kindMouseRelease
+	kindScrollX
+	kindScrollY
+	kindTextInput
+	kindTextEdit
+	kindMouseRelMove
+	kindClipboardUpdate
+	kindDropBegin
+	kindDrop
+	kindFingerDown
+	kindFingerUp
+	kindFingerMove
+	kindMultiGesture
+	// kindCodec is the kind of events encoded by registered event codecs.
+	kindCodec
+)
+
+// An eventWriter encodes timed events to an underlying writer.
+type eventWriter struct {
+	w *bufio.Writer
+	// The time of the previously written event.
+	prev time.Duration
+	// The first error encountered while writing.
+	err error
+}
+
+// newEventWriter returns a new event writer which writes to w.
+func newEventWriter(w io.Writer) *eventWriter {
+	return &eventWriter{w: bufio.NewWriter(w)}
+}
+
+// writeHeader writes the header of the event recording format.
+func (enc *eventWriter) writeHeader() (err error) {
+	enc.w.WriteString(recordMagic)
+	enc.w.WriteByte(recordVersion)
+	return enc.w.Flush()
+}
+
+// writeEvent encodes and writes the provided timed event. Events of unknown
+// types which are not handled by any registered event codec are silently
+// ignored.
+func (enc *eventWriter) writeEvent(event TimedEvent) {
+	if enc.err != nil {
+		return
+	}
+	var kind byte
+	var fields []int64
+	var strs []string
+	switch e := event.Event.(type) {
+	case we.Close:
+		kind = kindClose
+	case we.Resize:
+		kind = kindResize
+		fields = []int64{int64(e.Width), int64(e.Height)}
+	case we.MouseEnter:
+		kind = kindMouseEnter
+		fields = []int64{boolField(bool(e))}
+	case KeyPress:
+		kind = kindKeyPress
+		fields = []int64{int64(e.Key), int64(e.Mod), int64(e.Scancode)}
+	case KeyRepeat:
+		kind = kindKeyRepeat
+		fields = []int64{int64(e.Key), int64(e.Mod), int64(e.Scancode)}
+	case KeyRelease:
+		kind = kindKeyRelease
+		fields = []int64{int64(e.Key), int64(e.Mod), int64(e.Scancode)}
+	case ClipboardUpdate:
+		kind = kindClipboardUpdate
+	case DropBegin:
+		kind = kindDropBegin
+	case Drop:
+		kind = kindDrop
+		fields = []int64{int64(len(e.Files)), int64(len(e.Texts))}
+		strs = append(strs, e.Files...)
+		strs = append(strs, e.Texts...)
+	case FingerDown:
+		kind = kindFingerDown
+		fields = append([]int64{e.TouchID, e.FingerID}, pointFields(e.Point)...)
+		fields = append(fields, floatField(e.Pressure))
+	case FingerUp:
+		kind = kindFingerUp
+		fields = append([]int64{e.TouchID, e.FingerID}, pointFields(e.Point)...)
+		fields = append(fields, floatField(e.Pressure))
+	case FingerMove:
+		kind = kindFingerMove
+		fields = append([]int64{e.TouchID, e.FingerID}, pointFields(e.Point)...)
+		fields = append(fields, floatField(e.DX), floatField(e.DY), floatField(e.Pressure))
+	case MultiGesture:
+		kind = kindMultiGesture
+		fields = append([]int64{e.TouchID}, pointFields(e.Center)...)
+		fields = append(fields, floatField(e.Rotation), floatField(e.Pinch), int64(e.Fingers))
+	case we.KeyRune:
+		kind = kindKeyRune
+		fields = []int64{int64(e)}
+	case TextInput:
+		kind = kindTextInput
+		strs = []string{e.Text}
+	case TextEdit:
+		kind = kindTextEdit
+		fields = []int64{int64(e.Cursor), int64(e.Selection)}
+		strs = []string{e.Text}
+	case we.MouseMove:
+		kind = kindMouseMove
+		fields = pointFields(e.Point, e.From)
+	case we.MouseDrag:
+		kind = kindMouseDrag
+		fields = append(pointFields(e.Point, e.From), int64(e.Button), int64(e.Mod))
+	case MouseRelMove:
+		kind = kindMouseRelMove
+		fields = append(pointFields(e.Rel), int64(e.Buttons.state), int64(e.Mod))
+	case we.MousePress:
+		kind = kindMousePress
+		fields = append(pointFields(e.Point), int64(e.Button), int64(e.Mod))
+	case we.MouseRelease:
+		kind = kindMouseRelease
+		fields = append(pointFields(e.Point), int64(e.Button), int64(e.Mod))
+	case we.ScrollX:
+		kind = kindScrollX
+		fields = []int64{int64(e.Off), int64(e.Mod)}
+	case we.ScrollY:
+		kind = kindScrollY
+		fields = []int64{int64(e.Off), int64(e.Mod)}
+	default:
+		codec, codecFields, ok := encodeCodec(e)
+		if !ok {
+			// Ignore event.
+			return
+		}
+		if len(codecFields) > maxCodecFields {
+			enc.err = fmt.Errorf("win.StopRecording: %d fields of %q event exceeds maximum of %d", len(codecFields), codec.Name, maxCodecFields)
+			return
+		}
+		kind = kindCodec
+		fields = append([]int64{int64(len(codecFields))}, codecFields...)
+		strs = []string{codec.Name}
+	}
+
+	// Events may be injected with timestamps which precede the previous event.
+	var delta time.Duration
+	if event.Time > enc.prev {
+		delta = event.Time - enc.prev
+		enc.prev = event.Time
+	}
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(buf[:], uint64(delta/time.Millisecond))
+	enc.w.Write(buf[:n])
+	enc.w.WriteByte(kind)
+	for _, field := range fields {
+		n = binary.PutVarint(buf[:], field)
+		enc.w.Write(buf[:n])
+	}
+	for _, str := range strs {
+		n = binary.PutUvarint(buf[:], uint64(len(str)))
+		enc.w.Write(buf[:n])
+		enc.w.WriteString(str)
+	}
+	// Errors of bufio.Writer are sticky; record the first one.
+	if _, err := enc.w.Write(nil); err != nil {
+		enc.err = err
+	}
+}
+
+// boolField returns the event field representation of b.
+func boolField(b bool) int64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// floatField returns the event field representation of f.
+func floatField(f float64) int64 {
+	return int64(math.Float64bits(f))
+}
+
+// pointFields returns the event field representation of the provided points.
+func pointFields(pts ...image.Point) (fields []int64) {
+	for _, pt := range pts {
+		fields = append(fields, int64(pt.X), int64(pt.Y))
+	}
+	return fields
+}
+
+// An eventReader decodes timed events from an underlying reader.
+type eventReader struct {
+	r *bufio.Reader
+	// The time of the previously read event.
+	prev time.Duration
+}
+
+// readHeader reads and validates the header of the event recording format.
+func (dec *eventReader) readHeader() (err error) {
+	buf := make([]byte, len(recordMagic)+1)
+	_, err = io.ReadFull(dec.r, buf)
+	if err != nil {
+		return err
+	}
+	if string(buf[:len(recordMagic)]) != recordMagic {
+		return errors.New("win.ReadEvents: invalid event recording magic")
+	}
+	if version := buf[len(recordMagic)]; version != recordVersion {
+		return fmt.Errorf("win.ReadEvents: unsupported event recording version %d", version)
+	}
+	return nil
+}
+
+// readEvent reads and decodes the next timed event. It returns io.EOF if there
+// are no more events.
+func (dec *eventReader) readEvent() (event TimedEvent, err error) {
+	delta, err := binary.ReadUvarint(dec.r)
+	if err != nil {
+		return TimedEvent{}, err
+	}
+	kind, err := dec.r.ReadByte()
+	if err != nil {
+		return TimedEvent{}, io.ErrUnexpectedEOF
+	}
+	dec.prev += time.Duration(delta) * time.Millisecond
+	event.Time = dec.prev
+
+	// fields reads n varint encoded event fields.
+	fields := func(n int) (fs []int64, err error) {
+		fs = make([]int64, n)
+		for i := range fs {
+			fs[i], err = binary.ReadVarint(dec.r)
+			if err != nil {
+				return nil, io.ErrUnexpectedEOF
+			}
+		}
+		return fs, nil
+	}
+	// str reads a length-prefixed event string.
+	str := func() (string, error) {
+		n, err := binary.ReadUvarint(dec.r)
+		if err != nil {
+			return "", io.ErrUnexpectedEOF
+		}
+		if n > maxRecordString {
+			return "", fmt.Errorf("win.ReadEvents: event string length %d exceeds maximum of %d bytes", n, maxRecordString)
+		}
+		buf := make([]byte, n)
+		if _, err := io.ReadFull(dec.r, buf); err != nil {
+			return "", io.ErrUnexpectedEOF
+		}
+		return string(buf), nil
+	}
+	pt := func(fs []int64) image.Point {
+		return image.Pt(int(fs[0]), int(fs[1]))
+	}
+	float := func(field int64) float64 {
+		return math.Float64frombits(uint64(field))
+	}
+
+	var fs []int64
+	switch kind {
+	case kindClose:
+		event.Event = we.Close{}
+	case kindResize:
+		if fs, err = fields(2); err == nil {
+			event.Event = we.Resize{Width: int(fs[0]), Height: int(fs[1])}
+		}
+	case kindMouseEnter:
+		if fs, err = fields(1); err == nil {
+			event.Event = we.MouseEnter(fs[0] != 0)
+		}
+	case kindKeyPress:
+		if fs, err = fields(3); err == nil {
+			event.Event = KeyPress{
+				KeyPress: we.KeyPress{Key: we.Key(fs[0]), Mod: we.Mod(fs[1])},
+				Scancode: Scancode(fs[2]),
+			}
+		}
+	case kindKeyRepeat:
+		if fs, err = fields(3); err == nil {
+			event.Event = KeyRepeat{
+				KeyRepeat: we.KeyRepeat{Key: we.Key(fs[0]), Mod: we.Mod(fs[1])},
+				Scancode:  Scancode(fs[2]),
+			}
+		}
+	case kindKeyRelease:
+		if fs, err = fields(3); err == nil {
+			event.Event = KeyRelease{
+				KeyRelease: we.KeyRelease{Key: we.Key(fs[0]), Mod: we.Mod(fs[1])},
+				Scancode:   Scancode(fs[2]),
+			}
+		}
+	case kindClipboardUpdate:
+		event.Event = ClipboardUpdate{}
+	case kindDropBegin:
+		event.Event = DropBegin{}
+	case kindDrop:
+		if fs, err = fields(2); err == nil {
+			if fs[0] < 0 || fs[1] < 0 || fs[0] > maxRecordDrop || fs[1] > maxRecordDrop-fs[0] {
+				return TimedEvent{}, fmt.Errorf("win.ReadEvents: invalid number of dropped items (%d files, %d texts)", fs[0], fs[1])
+			}
+			d := Drop{}
+			for i := int64(0); i < fs[0]+fs[1] && err == nil; i++ {
+				var item string
+				if item, err = str(); err != nil {
+					break
+				}
+				if i < fs[0] {
+					d.Files = append(d.Files, item)
+				} else {
+					d.Texts = append(d.Texts, item)
+				}
+			}
+			event.Event = d
+		}
+	case kindFingerDown:
+		if fs, err = fields(5); err == nil {
+			event.Event = FingerDown{
+				TouchID:  fs[0],
+				FingerID: fs[1],
+				Point:    pt(fs[2:]),
+				Pressure: float(fs[4]),
+			}
+		}
+	case kindFingerUp:
+		if fs, err = fields(5); err == nil {
+			event.Event = FingerUp{
+				TouchID:  fs[0],
+				FingerID: fs[1],
+				Point:    pt(fs[2:]),
+				Pressure: float(fs[4]),
+			}
+		}
+	case kindFingerMove:
+		if fs, err = fields(7); err == nil {
+			event.Event = FingerMove{
+				TouchID:  fs[0],
+				FingerID: fs[1],
+				Point:    pt(fs[2:]),
+				DX:       float(fs[4]),
+				DY:       float(fs[5]),
+				Pressure: float(fs[6]),
+			}
+		}
+	case kindMultiGesture:
+		if fs, err = fields(6); err == nil {
+			event.Event = MultiGesture{
+				TouchID:  fs[0],
+				Center:   pt(fs[1:]),
+				Rotation: float(fs[3]),
+				Pinch:    float(fs[4]),
+				Fingers:  int(fs[5]),
+			}
+		}
+	case kindKeyRune:
+		if fs, err = fields(1); err == nil {
+			event.Event = we.KeyRune(fs[0])
+		}
+	case kindTextInput:
+		var text string
+		if text, err = str(); err == nil {
+			event.Event = TextInput{Text: text}
+		}
+	case kindTextEdit:
+		var text string
+		if fs, err = fields(2); err == nil {
+			if text, err = str(); err == nil {
+				event.Event = TextEdit{Text: text, Cursor: int(fs[0]), Selection: int(fs[1])}
+			}
+		}
+	case kindMouseMove:
+		if fs, err = fields(4); err == nil {
+			event.Event = we.MouseMove{Point: pt(fs[0:]), From: pt(fs[2:])}
+		}
+	case kindMouseDrag:
+		if fs, err = fields(6); err == nil {
+			event.Event = we.MouseDrag{
+				Point:  pt(fs[0:]),
+				From:   pt(fs[2:]),
+				Button: we.Button(fs[4]),
+				Mod:    we.Mod(fs[5]),
+			}
+		}
+	case kindMouseRelMove:
+		if fs, err = fields(4); err == nil {
+			event.Event = MouseRelMove{
+				Rel:     pt(fs),
+				Buttons: ButtonState{state: C.Uint32(fs[2])},
+				Mod:     we.Mod(fs[3]),
+			}
+		}
+	case kindMousePress:
+		if fs, err = fields(4); err == nil {
+			event.Event = we.MousePress{Point: pt(fs), Button: we.Button(fs[2]), Mod: we.Mod(fs[3])}
+		}
+	case kindMouseRelease:
+		if fs, err = fields(4); err == nil {
+			event.Event = we.MouseRelease{Point: pt(fs), Button: we.Button(fs[2]), Mod: we.Mod(fs[3])}
+		}
+	case kindScrollX:
+		if fs, err = fields(2); err == nil {
+			event.Event = we.ScrollX{Off: int(fs[0]), Mod: we.Mod(fs[1])}
+		}
+	case kindScrollY:
+		if fs, err = fields(2); err == nil {
+			event.Event = we.ScrollY{Off: int(fs[0]), Mod: we.Mod(fs[1])}
+		}
+	case kindCodec:
+		var n []int64
+		if n, err = fields(1); err == nil {
+			if n[0] < 0 || n[0] > maxCodecFields {
+				return TimedEvent{}, fmt.Errorf("win.ReadEvents: invalid number of event codec fields %d", n[0])
+			}
+			if fs, err = fields(int(n[0])); err == nil {
+				var name string
+				if name, err = str(); err == nil {
+					event.Event, err = decodeCodec(name, fs)
+				}
+			}
+		}
+	default:
+		return TimedEvent{}, fmt.Errorf("win.ReadEvents: unknown event kind %d", kind)
+	}
+	if err != nil {
+		return TimedEvent{}, err
+	}
+	return event, nil
+}
+
+// An EventCodec encodes and decodes the events of types defined outside of
+// package win in event recordings. It allows events delivered through
+// RegisterEventTranslator, such as those of win/controller, to be recorded and
+// replayed.
+type EventCodec struct {
+	// Name uniquely identifies the codec in event recordings.
+	Name string
+	// Encode returns the fields of the provided event and true, or false if
+	// the event is not handled by the codec. An event may have at most 64
+	// fields.
+	Encode func(event interface{}) (fields []int64, ok bool)
+	// Decode returns the event of the provided fields.
+	Decode func(fields []int64) (event interface{}, err error)
+}
+
+// codecs holds the event codecs registered through RegisterEventCodec.
+var codecs []EventCodec
+
+// RegisterEventCodec registers a codec which encodes and decodes events of
+// types not handled by package win in event recordings. Events which are not
+// handled by any codec are not recorded.
+func RegisterEventCodec(codec EventCodec) {
+	codecs = append(codecs, codec)
+}
+
+// encodeCodec encodes the provided event using the first registered event
+// codec which handles it. The boolean return value is false if no codec
+// handles the event.
+func encodeCodec(event interface{}) (codec EventCodec, fields []int64, ok bool) {
+	for _, codec := range codecs {
+		if fields, ok := codec.Encode(event); ok {
+			return codec, fields, true
+		}
+	}
+	return EventCodec{}, nil, false
+}
+
+// decodeCodec decodes the provided event fields using the registered event
+// codec of the provided name.
+func decodeCodec(name string, fields []int64) (event interface{}, err error) {
+	for _, codec := range codecs {
+		if codec.Name == name {
+			return codec.Decode(fields)
+		}
+	}
+	return nil, fmt.Errorf("win.ReadEvents: no event codec registered for %q events", name)
+}
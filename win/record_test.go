@@ -0,0 +1,161 @@
+package win
+
+import (
+	"bytes"
+	"image"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/mewmew/we"
+)
+
+// recordEvents holds an event of each kind of the event recording format.
+var recordEvents = []interface{}{
+	we.Close{},
+	we.Resize{Width: 640, Height: 480},
+	we.MouseEnter(true),
+	KeyPress{
+		KeyPress: we.KeyPress{Key: we.KeyW, Mod: we.ModShift},
+		Scancode: ScancodeW,
+	},
+	KeyRepeat{
+		KeyRepeat: we.KeyRepeat{Key: we.KeyW, Mod: we.ModShift | we.ModControl},
+		Scancode:  ScancodeW,
+	},
+	KeyRelease{
+		KeyRelease: we.KeyRelease{Key: we.KeyZ},
+		Scancode:   ScancodeW,
+	},
+	we.KeyRune('ö'),
+	we.MouseMove{Point: image.Pt(10, 20), From: image.Pt(-3, 25)},
+	we.MouseDrag{Point: image.Pt(11, 21), From: image.Pt(10, 20), Button: we.ButtonLeft, Mod: we.ModAlt},
+	we.MousePress{Point: image.Pt(11, 21), Button: we.ButtonRight, Mod: we.ModSuper},
+	we.MouseRelease{Point: image.Pt(12, 22), Button: we.ButtonRight},
+	we.ScrollX{Off: -2, Mod: we.ModShift},
+	we.ScrollY{Off: 3},
+	TextInput{Text: "héllo, 世界"},
+	TextEdit{Text: "にほんご", Cursor: 2, Selection: 1},
+	MouseRelMove{Rel: image.Pt(-7, 4), Buttons: ButtonState{state: 5}, Mod: we.ModControl},
+	ClipboardUpdate{},
+	DropBegin{},
+	Drop{Files: []string{"/tmp/a.png", "/tmp/b c.png"}, Texts: []string{"text"}},
+	Drop{Files: []string{"/tmp/only.png"}},
+	FingerDown{TouchID: 1, FingerID: -2, Point: image.Pt(30, 40), Pressure: 0.5},
+	FingerUp{TouchID: 1, FingerID: -2, Point: image.Pt(31, 41), Pressure: 1.0 / 3},
+	FingerMove{TouchID: 1, FingerID: 3, Point: image.Pt(32, 42), DX: -0.25, DY: 1.5, Pressure: math.Pi / 4},
+	MultiGesture{TouchID: 4, Center: image.Pt(50, 60), Rotation: -0.25, Pinch: math.SmallestNonzeroFloat64, Fingers: 2},
+}
+
+func TestRecordReplay(t *testing.T) {
+	openHeadless(t, 64, 48)
+
+	// Record the events 10 ms apart.
+	const step = 10 * time.Millisecond
+	start := 2 * time.Second
+	buf := new(bytes.Buffer)
+	err := StartRecording(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, event := range recordEvents {
+		record(start+time.Duration(i)*step, event)
+	}
+	err = StopRecording()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events, err := ReadEvents(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != len(recordEvents) {
+		t.Fatalf("number of events mismatch; expected %d, got %d", len(recordEvents), len(events))
+	}
+	for i, event := range events {
+		if want := start + time.Duration(i)*step; event.Time != want {
+			t.Errorf("event %d: time mismatch; expected %v, got %v", i, want, event.Time)
+		}
+		if !reflect.DeepEqual(event.Event, recordEvents[i]) {
+			t.Errorf("event %d: mismatch; expected %#v, got %#v", i, recordEvents[i], event.Event)
+		}
+	}
+
+	// Replay the events, advancing the clock one step at a time.
+	clock := new(ManualClock)
+	StartReplay(events, clock)
+	defer StopReplay()
+	for i, want := range recordEvents {
+		if i > 0 {
+			if event := PollEvent(); event != nil {
+				t.Fatalf("event %d: replayed before due; got %#v", i, event)
+			}
+			clock.Advance(step)
+		}
+		event := PollEvent()
+		if !reflect.DeepEqual(event, want) {
+			t.Errorf("event %d: replay mismatch; expected %#v, got %#v", i, want, event)
+		}
+	}
+	if Replaying() {
+		t.Error("replay still in progress after all events were returned")
+	}
+}
+
+func TestInjectEvent(t *testing.T) {
+	openHeadless(t, 64, 48)
+	InjectEvent(we.Resize{Width: 1, Height: 2})
+	InjectEvent(TextInput{Text: "a"})
+	for _, want := range []interface{}{we.Resize{Width: 1, Height: 2}, TextInput{Text: "a"}} {
+		if event := PollEvent(); !reflect.DeepEqual(event, want) {
+			t.Errorf("injected event mismatch; expected %#v, got %#v", want, event)
+		}
+	}
+}
+
+func TestReadEventsInvalid(t *testing.T) {
+	golden := new(bytes.Buffer)
+	enc := newEventWriter(golden)
+	if err := enc.writeHeader(); err != nil {
+		t.Fatal(err)
+	}
+	enc.writeEvent(TimedEvent{Event: TextInput{Text: "abc"}})
+	if err := enc.w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	data := golden.Bytes()
+
+	// A recording truncated within an event must report an error.
+	for n := len(recordMagic) + 2; n < len(data); n++ {
+		if _, err := ReadEvents(bytes.NewReader(data[:n])); err == nil {
+			t.Errorf("truncated recording of %d bytes; expected error, got nil", n)
+		}
+	}
+	if _, err := ReadEvents(bytes.NewReader([]byte("WEVT\xFF"))); err == nil {
+		t.Error("unsupported version; expected error, got nil")
+	}
+}
+
+func TestReadEventsLongString(t *testing.T) {
+	// A text input event whose string length exceeds the file size.
+	data := append([]byte(recordMagic), recordVersion)
+	data = append(data, 0, kindTextInput)
+	data = append(data, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0x7F)
+	if _, err := ReadEvents(bytes.NewReader(data)); err == nil {
+		t.Error("huge string length; expected error, got nil")
+	}
+}
+
+func TestReadEventsDropCount(t *testing.T) {
+	// Drop events with a negative and a huge number of dropped items.
+	for _, counts := range [][]byte{{0x01, 0x00}, {0x00, 0xFE, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0x01}} {
+		data := append([]byte(recordMagic), recordVersion)
+		data = append(data, 0, kindDrop)
+		data = append(data, counts...)
+		if _, err := ReadEvents(bytes.NewReader(data)); err == nil {
+			t.Errorf("drop counts % X; expected error, got nil", counts)
+		}
+	}
+}
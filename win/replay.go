@@ -0,0 +1,130 @@
+package win
+
+// #cgo pkg-config: sdl2
+// #include <SDL2/SDL.h>
+import "C"
+
+import (
+	"time"
+
+	"github.com/mewmew/we"
+)
+
+// A Clock reports the time elapsed since the start of an event replay.
+type Clock interface {
+	// Elapsed returns the time elapsed since the start of the replay.
+	Elapsed() time.Duration
+}
+
+// realClock is a replay clock which follows the wall clock.
+type realClock struct {
+	// The start time of the replay.
+	start time.Time
+}
+
+// Elapsed returns the wall clock time elapsed since the start of the replay.
+func (clock realClock) Elapsed() time.Duration {
+	return time.Since(clock.start)
+}
+
+// A ManualClock is a replay clock which only advances when told to. It is
+// useful for deterministic replays in tests, where each frame may advance the
+// clock by a fixed amount of time.
+type ManualClock struct {
+	// The time elapsed since the start of the replay.
+	elapsed time.Duration
+}
+
+// Elapsed returns the time elapsed since the start of the replay.
+func (clock *ManualClock) Elapsed() time.Duration {
+	return clock.elapsed
+}
+
+// Advance advances the clock by d.
+func (clock *ManualClock) Advance(d time.Duration) {
+	clock.elapsed += d
+}
+
+// replay is the active event replay, or nil if no replay is in progress.
+var replay *eventReplay
+
+// An eventReplay feeds recorded events to PollEvent.
+type eventReplay struct {
+	// Pending events of the replay.
+	events []TimedEvent
+	// The time of the first recorded event.
+	start time.Duration
+	// The replay clock.
+	clock Clock
+}
+
+// StartReplay starts to replay the provided events, as read by ReadEvents. For
+// the duration of the replay PollEvent returns the recorded events in place of
+// real input, at the times dictated by clock relative to the first recorded
+// event. The replay follows the wall clock if clock is nil.
+//
+// Real input is discarded during the replay, with the exception of close
+// events. The replay may run headless by selecting the dummy video driver of
+// SDL, e.g. by setting the SDL_VIDEODRIVER environment variable to "dummy".
+func StartReplay(events []TimedEvent, clock Clock) {
+	if clock == nil {
+		clock = realClock{start: time.Now()}
+	}
+	replay = &eventReplay{
+		events: events,
+		clock:  clock,
+	}
+	if len(events) > 0 {
+		replay.start = events[0].Time
+	}
+}
+
+// StopReplay stops the active event replay, if any. Real input is once again
+// returned by PollEvent.
+func StopReplay() {
+	replay = nil
+}
+
+// Replaying returns true if an event replay is in progress, and false
+// otherwise. The replay is stopped automatically once all recorded events have
+// been returned by PollEvent.
+func Replaying() bool {
+	return replay != nil
+}
+
+// poll returns the next recorded event which is due according to the replay
+// clock, or nil if no such event exists.
+func (r *eventReplay) poll() (event interface{}) {
+	// Discard real input but keep the window responsive to close events.
+	for len(queued) > 0 {
+		ev := queued[0].Event
+		queued = queued[1:]
+		if ev, ok := ev.(we.Close); ok {
+			record(ticks(), ev)
+			return ev
+		}
+	}
+	e := new(C.SDL_Event)
+	for C.SDL_PollEvent(e) == 1 {
+		if ev, ok := goEvent(e).(we.Close); ok {
+			record(ticks(), ev)
+			return ev
+		}
+	}
+
+	if len(r.events) == 0 {
+		replay = nil
+		return nil
+	}
+	next := r.events[0]
+	if next.Time-r.start > r.clock.Elapsed() {
+		// The next event is not yet due.
+		return nil
+	}
+	r.events = r.events[1:]
+	if len(r.events) == 0 {
+		replay = nil
+	}
+	record(next.Time, next.Event)
+	return next.Event
+}
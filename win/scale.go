@@ -0,0 +1,143 @@
+package win
+
+// #cgo pkg-config: sdl2
+// #include <SDL2/SDL.h>
+import "C"
+
+import (
+	"fmt"
+	"image"
+	"math"
+
+	xdraw "golang.org/x/image/draw"
+)
+
+// Filter specifies the interpolation filter used when resampling images.
+type Filter int
+
+// Interpolation filters, from fastest to highest quality.
+const (
+	// NearestNeighbor picks the nearest source pixel. It is fast and keeps
+	// pixel art crisp, but produces jagged edges.
+	NearestNeighbor Filter = iota
+	// BiLinear interpolates linearly between the four nearest source pixels.
+	BiLinear
+	// CatmullRom uses the Catmull-Rom cubic kernel, which is slow but sharp.
+	CatmullRom
+	// Lanczos uses the Lanczos kernel with three lobes, which is the slowest
+	// but best preserves detail when downscaling.
+	Lanczos
+)
+
+// lanczos3 is the Lanczos resampling kernel with three lobes.
+var lanczos3 = &xdraw.Kernel{
+	Support: 3,
+	At: func(t float64) float64 {
+		if t == 0 {
+			return 1
+		}
+		if t < -3 || t > 3 {
+			return 0
+		}
+		x := math.Pi * t
+		return 3 * math.Sin(x) * math.Sin(x/3) / (x * x)
+	},
+}
+
+// interpolator returns the x/image/draw interpolator of the filter.
+func (filter Filter) interpolator() (xdraw.Interpolator, error) {
+	switch filter {
+	case NearestNeighbor:
+		return xdraw.NearestNeighbor, nil
+	case BiLinear:
+		return xdraw.BiLinear, nil
+	case CatmullRom:
+		return xdraw.CatmullRom, nil
+	case Lanczos:
+		return lanczos3, nil
+	}
+	return nil, fmt.Errorf("win.Filter.interpolator: invalid filter %d", filter)
+}
+
+// DrawScaled fills the destination rectangle dr of the dst image with the
+// pixels of the source rectangle sr of the src image, scaled to fit using
+// nearest neighbor interpolation. The entire src image is used if sr is empty.
+// Nothing is drawn if dr is empty.
+func (dst *Image) DrawScaled(dr image.Rectangle, src *Image, sr image.Rectangle) (err error) {
+	if dr.Empty() {
+		// A nil destination rectangle would stretch src over all of dst.
+		return nil
+	}
+	if sr.Empty() {
+		sr = image.ZR
+	}
+	srcRect := cRect(sr)
+	dstRect := cRect(dr)
+	if C.SDL_BlitScaled(src.s, srcRect, dst.s, dstRect) != 0 {
+		return getError()
+	}
+	return nil
+}
+
+// DrawScaledFilter fills the destination rectangle dr of the dst image with the
+// pixels of the source rectangle sr of the src image, scaled to fit using the
+// provided interpolation filter. The entire src image is used if sr is empty.
+// Nothing is drawn if dr is empty.
+//
+// Filters other than NearestNeighbor are implemented in Go, and are therefore
+// considerably slower than DrawScaled. Prefer scaling images once at load time
+// using Scale. The blend mode, and alpha and color modulation of src are
+// respected by all filters, and its color key is treated as transparency.
+func (dst *Image) DrawScaledFilter(dr image.Rectangle, src *Image, sr image.Rectangle, filter Filter) (err error) {
+	if dr.Empty() {
+		return nil
+	}
+	if filter == NearestNeighbor {
+		return dst.DrawScaled(dr, src, sr)
+	}
+	if sr.Empty() {
+		sr = image.Rect(0, 0, src.Width, src.Height)
+	}
+	scaled, err := src.scale(sr, dr.Dx(), dr.Dy(), filter)
+	if err != nil {
+		return err
+	}
+	defer scaled.Free()
+	return dst.Draw(dr.Min, scaled)
+}
+
+// Scale returns a copy of the image scaled to the specified dimensions, using
+// the provided interpolation filter. The copy has a 32-bit pixel format, with an
+// alpha channel if needed. It inherits the blend mode, and alpha and color
+// modulation of the image, and its color key is converted to transparent
+// pixels, which are blended with neighbouring pixels by the filter.
+//
+// Note: The Free method of the returned image should be called when finished
+// using it.
+func (img *Image) Scale(width, height int, filter Filter) (scaled *Image, err error) {
+	return img.scale(image.Rect(0, 0, img.Width, img.Height), width, height, filter)
+}
+
+// scale returns a copy of the source rectangle sr of the image scaled to the
+// specified dimensions, using the provided interpolation filter.
+func (img *Image) scale(sr image.Rectangle, width, height int, filter Filter) (scaled *Image, err error) {
+	interp, err := filter.interpolator()
+	if err != nil {
+		return nil, err
+	}
+	src, err := surfaceNRGBA(img.s)
+	if err != nil {
+		return nil, err
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+	interp.Scale(dst, dst.Bounds(), src, sr, xdraw.Src, nil)
+	scaled, err = ReadImage(dst)
+	if err != nil {
+		return nil, err
+	}
+	if err := scaled.inheritConverted(img); err != nil {
+		scaled.Free()
+		return nil, err
+	}
+	return scaled, nil
+}
@@ -0,0 +1,132 @@
+package win
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// newTestImage returns an image of the provided Go image.
+func newTestImage(t testing.TB, src image.Image) *Image {
+	t.Helper()
+	img, err := ReadImage(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(img.Free)
+	return img
+}
+
+// toNRGBA returns a copy of the pixels of the image.
+func toNRGBA(t testing.TB, img *Image) *image.NRGBA {
+	t.Helper()
+	dst, err := img.ToNRGBA()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dst
+}
+
+// colorDiff returns the maximum difference between the channels of a and b.
+func colorDiff(a, b color.NRGBA) int {
+	diff := 0
+	for _, d := range []int{int(a.R) - int(b.R), int(a.G) - int(b.G), int(a.B) - int(b.B), int(a.A) - int(b.A)} {
+		if d < 0 {
+			d = -d
+		}
+		if d > diff {
+			diff = d
+		}
+	}
+	return diff
+}
+
+func TestDrawScaledEmptySource(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(0, 0, 2, 2))
+	src.SetNRGBA(1, 1, color.NRGBA{R: 0xFF, A: 0xFF})
+	img := newTestImage(t, src)
+	dst := newTestImage(t, image.NewNRGBA(image.Rect(0, 0, 4, 4)))
+	// A non-zero empty source rectangle selects the entire image.
+	if err := dst.DrawScaled(image.Rect(0, 0, 4, 4), img, image.Rect(1, 1, 1, 1)); err != nil {
+		t.Fatal(err)
+	}
+	got := toNRGBA(t, dst)
+	if c := got.NRGBAAt(3, 3); c != (color.NRGBA{R: 0xFF, A: 0xFF}) {
+		t.Errorf("pixel (3, 3) mismatch; expected red, got %v", c)
+	}
+}
+
+func TestDrawScaledEmptyDest(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(0, 0, 2, 2))
+	for i := range src.Pix {
+		src.Pix[i] = 0xFF
+	}
+	img := newTestImage(t, src)
+	for _, filter := range []Filter{NearestNeighbor, BiLinear} {
+		dst := newTestImage(t, image.NewNRGBA(image.Rect(0, 0, 4, 4)))
+		for _, dr := range []image.Rectangle{image.ZR, image.Rect(2, 2, 2, 4)} {
+			if err := dst.DrawScaledFilter(dr, img, image.ZR, filter); err != nil {
+				t.Fatal(err)
+			}
+		}
+		got := toNRGBA(t, dst)
+		for i, v := range got.Pix {
+			if v != 0 {
+				t.Fatalf("filter %d: byte %d mismatch; expected nothing drawn, got %#x", filter, i, v)
+			}
+		}
+	}
+}
+
+func TestDrawScaledFilterState(t *testing.T) {
+	// An opaque image with a red left half, which is color keyed, and a green
+	// right half, which is modulated.
+	src := image.NewNRGBA(image.Rect(0, 0, 8, 8))
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			c := color.NRGBA{R: 0xFF, A: 0xFF}
+			if x >= 4 {
+				c = color.NRGBA{G: 0xFF, A: 0xFF}
+			}
+			src.SetNRGBA(x, y, c)
+		}
+	}
+	img := newTestImage(t, src)
+	if err := img.SetColorKey(color.NRGBA{R: 0xFF, A: 0xFF}); err != nil {
+		t.Fatal(err)
+	}
+	if err := img.SetColorMod(0xFF, 0x80, 0xFF); err != nil {
+		t.Fatal(err)
+	}
+	if err := img.SetAlphaMod(0xC0); err != nil {
+		t.Fatal(err)
+	}
+
+	// Pixels away from the edge between the halves must be the same for all
+	// filters.
+	bg := image.NewUniform(color.NRGBA{B: 0xFF, A: 0xFF})
+	var want *image.NRGBA
+	for _, filter := range []Filter{NearestNeighbor, BiLinear, CatmullRom, Lanczos} {
+		dst := newTestImage(t, image.NewNRGBA(image.Rect(0, 0, 16, 16)))
+		if err := dst.Fill(bg.C); err != nil {
+			t.Fatal(err)
+		}
+		if err := dst.DrawScaledFilter(image.Rect(0, 0, 16, 16), img, image.ZR, filter); err != nil {
+			t.Fatal(err)
+		}
+		got := toNRGBA(t, dst)
+		if want == nil {
+			want = got
+			if c := want.NRGBAAt(0, 0); c != bg.C {
+				t.Fatalf("color keyed pixel mismatch; expected %v, got %v", bg.C, c)
+			}
+			continue
+		}
+		for _, x := range []int{0, 1, 2, 3, 12, 13, 14, 15} {
+			w, g := want.NRGBAAt(x, 8), got.NRGBAAt(x, 8)
+			if colorDiff(w, g) > 2 {
+				t.Errorf("filter %d: pixel (%d, 8) mismatch; expected %v, got %v", filter, x, w, g)
+			}
+		}
+	}
+}
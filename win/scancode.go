@@ -0,0 +1,181 @@
+package win
+
+// #cgo pkg-config: sdl2
+// #include <SDL2/SDL.h>
+import "C"
+
+import (
+	"github.com/mewmew/we"
+)
+
+// A Scancode identifies the physical location of a key on the keyboard,
+// independent of the current keyboard layout. The scancode of the W key on a
+// QWERTY keyboard is for instance identical to the scancode of the Z key on an
+// AZERTY keyboard.
+//
+// Scancode values are based on the USB usage page standard.
+type Scancode int
+
+// Scancodes. The names of the scancodes correspond to the keys of a US QWERTY
+// keyboard layout.
+const (
+	// ScancodeUnknown represents an unknown scancode.
+	ScancodeUnknown Scancode = C.SDL_SCANCODE_UNKNOWN
+
+	// Letter keys.
+	ScancodeA Scancode = C.SDL_SCANCODE_A
+	ScancodeB Scancode = C.SDL_SCANCODE_B
+	ScancodeC Scancode = C.SDL_SCANCODE_C
+	ScancodeD Scancode = C.SDL_SCANCODE_D
+	ScancodeE Scancode = C.SDL_SCANCODE_E
+	ScancodeF Scancode = C.SDL_SCANCODE_F
+	ScancodeG Scancode = C.SDL_SCANCODE_G
+	ScancodeH Scancode = C.SDL_SCANCODE_H
+	ScancodeI Scancode = C.SDL_SCANCODE_I
+	ScancodeJ Scancode = C.SDL_SCANCODE_J
+	ScancodeK Scancode = C.SDL_SCANCODE_K
+	ScancodeL Scancode = C.SDL_SCANCODE_L
+	ScancodeM Scancode = C.SDL_SCANCODE_M
+	ScancodeN Scancode = C.SDL_SCANCODE_N
+	ScancodeO Scancode = C.SDL_SCANCODE_O
+	ScancodeP Scancode = C.SDL_SCANCODE_P
+	ScancodeQ Scancode = C.SDL_SCANCODE_Q
+	ScancodeR Scancode = C.SDL_SCANCODE_R
+	ScancodeS Scancode = C.SDL_SCANCODE_S
+	ScancodeT Scancode = C.SDL_SCANCODE_T
+	ScancodeU Scancode = C.SDL_SCANCODE_U
+	ScancodeV Scancode = C.SDL_SCANCODE_V
+	ScancodeW Scancode = C.SDL_SCANCODE_W
+	ScancodeX Scancode = C.SDL_SCANCODE_X
+	ScancodeY Scancode = C.SDL_SCANCODE_Y
+	ScancodeZ Scancode = C.SDL_SCANCODE_Z
+
+	// Number keys.
+	Scancode1 Scancode = C.SDL_SCANCODE_1
+	Scancode2 Scancode = C.SDL_SCANCODE_2
+	Scancode3 Scancode = C.SDL_SCANCODE_3
+	Scancode4 Scancode = C.SDL_SCANCODE_4
+	Scancode5 Scancode = C.SDL_SCANCODE_5
+	Scancode6 Scancode = C.SDL_SCANCODE_6
+	Scancode7 Scancode = C.SDL_SCANCODE_7
+	Scancode8 Scancode = C.SDL_SCANCODE_8
+	Scancode9 Scancode = C.SDL_SCANCODE_9
+	Scancode0 Scancode = C.SDL_SCANCODE_0
+
+	// Printable keys.
+	ScancodeReturn         Scancode = C.SDL_SCANCODE_RETURN
+	ScancodeEscape         Scancode = C.SDL_SCANCODE_ESCAPE
+	ScancodeBackspace      Scancode = C.SDL_SCANCODE_BACKSPACE
+	ScancodeTab            Scancode = C.SDL_SCANCODE_TAB
+	ScancodeSpace          Scancode = C.SDL_SCANCODE_SPACE
+	ScancodeMinus          Scancode = C.SDL_SCANCODE_MINUS
+	ScancodeEquals         Scancode = C.SDL_SCANCODE_EQUALS
+	ScancodeLeftBracket    Scancode = C.SDL_SCANCODE_LEFTBRACKET
+	ScancodeRightBracket   Scancode = C.SDL_SCANCODE_RIGHTBRACKET
+	ScancodeBackslash      Scancode = C.SDL_SCANCODE_BACKSLASH
+	ScancodeNonUSHash      Scancode = C.SDL_SCANCODE_NONUSHASH
+	ScancodeSemicolon      Scancode = C.SDL_SCANCODE_SEMICOLON
+	ScancodeApostrophe     Scancode = C.SDL_SCANCODE_APOSTROPHE
+	ScancodeGrave          Scancode = C.SDL_SCANCODE_GRAVE
+	ScancodeComma          Scancode = C.SDL_SCANCODE_COMMA
+	ScancodePeriod         Scancode = C.SDL_SCANCODE_PERIOD
+	ScancodeSlash          Scancode = C.SDL_SCANCODE_SLASH
+	ScancodeNonUSBackslash Scancode = C.SDL_SCANCODE_NONUSBACKSLASH
+
+	// Function keys.
+	ScancodeCapsLock    Scancode = C.SDL_SCANCODE_CAPSLOCK
+	ScancodeF1          Scancode = C.SDL_SCANCODE_F1
+	ScancodeF2          Scancode = C.SDL_SCANCODE_F2
+	ScancodeF3          Scancode = C.SDL_SCANCODE_F3
+	ScancodeF4          Scancode = C.SDL_SCANCODE_F4
+	ScancodeF5          Scancode = C.SDL_SCANCODE_F5
+	ScancodeF6          Scancode = C.SDL_SCANCODE_F6
+	ScancodeF7          Scancode = C.SDL_SCANCODE_F7
+	ScancodeF8          Scancode = C.SDL_SCANCODE_F8
+	ScancodeF9          Scancode = C.SDL_SCANCODE_F9
+	ScancodeF10         Scancode = C.SDL_SCANCODE_F10
+	ScancodeF11         Scancode = C.SDL_SCANCODE_F11
+	ScancodeF12         Scancode = C.SDL_SCANCODE_F12
+	ScancodeF13         Scancode = C.SDL_SCANCODE_F13
+	ScancodeF14         Scancode = C.SDL_SCANCODE_F14
+	ScancodeF15         Scancode = C.SDL_SCANCODE_F15
+	ScancodeF16         Scancode = C.SDL_SCANCODE_F16
+	ScancodeF17         Scancode = C.SDL_SCANCODE_F17
+	ScancodeF18         Scancode = C.SDL_SCANCODE_F18
+	ScancodeF19         Scancode = C.SDL_SCANCODE_F19
+	ScancodeF20         Scancode = C.SDL_SCANCODE_F20
+	ScancodeF21         Scancode = C.SDL_SCANCODE_F21
+	ScancodeF22         Scancode = C.SDL_SCANCODE_F22
+	ScancodeF23         Scancode = C.SDL_SCANCODE_F23
+	ScancodeF24         Scancode = C.SDL_SCANCODE_F24
+	ScancodePrintScreen Scancode = C.SDL_SCANCODE_PRINTSCREEN
+	ScancodeScrollLock  Scancode = C.SDL_SCANCODE_SCROLLLOCK
+	ScancodePause       Scancode = C.SDL_SCANCODE_PAUSE
+	ScancodeInsert      Scancode = C.SDL_SCANCODE_INSERT
+	ScancodeHome        Scancode = C.SDL_SCANCODE_HOME
+	ScancodePageUp      Scancode = C.SDL_SCANCODE_PAGEUP
+	ScancodeDelete      Scancode = C.SDL_SCANCODE_DELETE
+	ScancodeEnd         Scancode = C.SDL_SCANCODE_END
+	ScancodePageDown    Scancode = C.SDL_SCANCODE_PAGEDOWN
+	ScancodeRight       Scancode = C.SDL_SCANCODE_RIGHT
+	ScancodeLeft        Scancode = C.SDL_SCANCODE_LEFT
+	ScancodeDown        Scancode = C.SDL_SCANCODE_DOWN
+	ScancodeUp          Scancode = C.SDL_SCANCODE_UP
+	ScancodeMenu        Scancode = C.SDL_SCANCODE_APPLICATION
+
+	// Keypad keys.
+	ScancodeNumLock    Scancode = C.SDL_SCANCODE_NUMLOCKCLEAR
+	ScancodeKpDivide   Scancode = C.SDL_SCANCODE_KP_DIVIDE
+	ScancodeKpMultiply Scancode = C.SDL_SCANCODE_KP_MULTIPLY
+	ScancodeKpSubtract Scancode = C.SDL_SCANCODE_KP_MINUS
+	ScancodeKpAdd      Scancode = C.SDL_SCANCODE_KP_PLUS
+	ScancodeKpEnter    Scancode = C.SDL_SCANCODE_KP_ENTER
+	ScancodeKp1        Scancode = C.SDL_SCANCODE_KP_1
+	ScancodeKp2        Scancode = C.SDL_SCANCODE_KP_2
+	ScancodeKp3        Scancode = C.SDL_SCANCODE_KP_3
+	ScancodeKp4        Scancode = C.SDL_SCANCODE_KP_4
+	ScancodeKp5        Scancode = C.SDL_SCANCODE_KP_5
+	ScancodeKp6        Scancode = C.SDL_SCANCODE_KP_6
+	ScancodeKp7        Scancode = C.SDL_SCANCODE_KP_7
+	ScancodeKp8        Scancode = C.SDL_SCANCODE_KP_8
+	ScancodeKp9        Scancode = C.SDL_SCANCODE_KP_9
+	ScancodeKp0        Scancode = C.SDL_SCANCODE_KP_0
+	ScancodeKpDecimal  Scancode = C.SDL_SCANCODE_KP_PERIOD
+	ScancodeKpEqual    Scancode = C.SDL_SCANCODE_KP_EQUALS
+
+	// Modifier keys.
+	ScancodeLeftControl  Scancode = C.SDL_SCANCODE_LCTRL
+	ScancodeLeftShift    Scancode = C.SDL_SCANCODE_LSHIFT
+	ScancodeLeftAlt      Scancode = C.SDL_SCANCODE_LALT
+	ScancodeLeftSuper    Scancode = C.SDL_SCANCODE_LGUI
+	ScancodeRightControl Scancode = C.SDL_SCANCODE_RCTRL
+	ScancodeRightShift   Scancode = C.SDL_SCANCODE_RSHIFT
+	ScancodeRightAlt     Scancode = C.SDL_SCANCODE_RALT
+	ScancodeRightSuper   Scancode = C.SDL_SCANCODE_RGUI
+)
+
+// Key returns the key which corresponds to the scancode in the current keyboard
+// layout.
+func (scancode Scancode) Key() (key we.Key) {
+	return goKey(C.SDL_GetKeyFromScancode(C.SDL_Scancode(scancode)))
+}
+
+// Name returns a human-readable name of the key which corresponds to the
+// scancode in the current keyboard layout, or an empty string if no name
+// exists. It is suitable for display in key binding menus.
+func (scancode Scancode) Name() string {
+	keycode := C.SDL_GetKeyFromScancode(C.SDL_Scancode(scancode))
+	return C.GoString(C.SDL_GetKeyName(keycode))
+}
+
+// ScancodeFromKey returns the scancode which corresponds to the provided key in
+// the current keyboard layout.
+func ScancodeFromKey(key we.Key) (scancode Scancode) {
+	return Scancode(C.SDL_GetScancodeFromKey(cKey(key)))
+}
+
+// KeyName returns a human-readable name of the provided key, or an empty string
+// if no name exists.
+func KeyName(key we.Key) string {
+	return C.GoString(C.SDL_GetKeyName(cKey(key)))
+}
@@ -0,0 +1,274 @@
+package win
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// A Sprite is a frame of a sprite sheet.
+type Sprite struct {
+	// The name of the sprite, or an empty string if unnamed.
+	Name string
+	// The location of the sprite within the sprite sheet image.
+	Rect image.Rectangle
+	// The offset of the sprite within its original frame, from which
+	// transparent borders may have been trimmed; the sprite should be drawn
+	// at this offset to keep trimmed animation frames aligned.
+	Offset image.Point
+	// The dimensions of the original frame, which equal those of Rect unless
+	// transparent borders have been trimmed.
+	Size image.Point
+	// The duration of the sprite when played as an animation frame, or 0 if
+	// unspecified.
+	Duration time.Duration
+}
+
+// A SpriteSheet is an image which contains several sprites, such as the frames
+// of an animation. The sprites are views of the sprite sheet image, which may
+// therefore not be RLE accelerated; see Image.SetRLE.
+type SpriteSheet struct {
+	// The sprite sheet image.
+	Image *Image
+	// The sprites of the sprite sheet.
+	Sprites []Sprite
+	// Sub-image views of the sprites, which are created on first use.
+	views []*Image
+	// owned specifies whether the sprite sheet image is freed by Free.
+	owned bool
+}
+
+// NewSpriteSheet returns a sprite sheet which slices the provided image into a
+// grid of sprites of the specified dimensions. The sprites are ordered from
+// left to right and from top to bottom; partial sprites at the right and
+// bottom edges are ignored.
+//
+// Note: The Free method of the sprite sheet should be called when finished
+// using it. It does not free img.
+func NewSpriteSheet(img *Image, spriteWidth, spriteHeight int) (sheet *SpriteSheet, err error) {
+	if spriteWidth <= 0 || spriteHeight <= 0 {
+		return nil, fmt.Errorf("win.NewSpriteSheet: invalid sprite dimensions %dx%d", spriteWidth, spriteHeight)
+	}
+	sheet = &SpriteSheet{Image: img}
+	for y := 0; y+spriteHeight <= img.Height; y += spriteHeight {
+		for x := 0; x+spriteWidth <= img.Width; x += spriteWidth {
+			sprite := Sprite{
+				Rect: image.Rect(x, y, x+spriteWidth, y+spriteHeight),
+				Size: image.Pt(spriteWidth, spriteHeight),
+			}
+			sheet.Sprites = append(sheet.Sprites, sprite)
+		}
+	}
+	return sheet, nil
+}
+
+// ReadSpriteSheet reads the sprite locations of the provided image from a JSON
+// sprite sheet description in r. Both the hash and the array variants of the
+// JSON formats of Aseprite and TexturePacker are supported, including trimmed
+// sprites. Rotated sprites are not supported.
+//
+// Note: The Free method of the sprite sheet should be called when finished
+// using it. It does not free img.
+func ReadSpriteSheet(img *Image, r io.Reader) (sheet *SpriteSheet, err error) {
+	desc, err := parseSheet(r)
+	if err != nil {
+		return nil, err
+	}
+	return &SpriteSheet{Image: img, Sprites: desc.sprites}, nil
+}
+
+// LoadSpriteSheet loads the provided JSON sprite sheet description, in the
+// format of Aseprite or TexturePacker, together with the image it refers to.
+// The image path is relative to the directory of the JSON file.
+//
+// Note: The Free method of the sprite sheet should be called when finished
+// using it. It also frees the sprite sheet image.
+func LoadSpriteSheet(jsonPath string) (sheet *SpriteSheet, err error) {
+	f, err := os.Open(jsonPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	desc, err := parseSheet(f)
+	if err != nil {
+		return nil, err
+	}
+	if desc.imgPath == "" {
+		return nil, fmt.Errorf("win.LoadSpriteSheet: missing image path in %q", jsonPath)
+	}
+	img, err := LoadImage(filepath.Join(filepath.Dir(jsonPath), desc.imgPath))
+	if err != nil {
+		return nil, err
+	}
+	sheet = &SpriteSheet{Image: img, Sprites: desc.sprites, owned: true}
+	return sheet, nil
+}
+
+// Free frees the sprite views of the sprite sheet, and the sprite sheet image
+// if it was loaded by LoadSpriteSheet.
+func (sheet *SpriteSheet) Free() {
+	for _, view := range sheet.views {
+		if view != nil {
+			view.Free()
+		}
+	}
+	sheet.views = nil
+	if sheet.owned {
+		sheet.Image.Free()
+	}
+}
+
+// Sprite returns an image of the i:th sprite of the sprite sheet. The image is
+// a view which shares its pixels with the sprite sheet image; see
+// Image.SubImage. It is owned by the sprite sheet and must not be freed.
+func (sheet *SpriteSheet) Sprite(i int) (img *Image, err error) {
+	if i < 0 || i >= len(sheet.Sprites) {
+		return nil, fmt.Errorf("win.SpriteSheet.Sprite: sprite index %d out of range [0, %d)", i, len(sheet.Sprites))
+	}
+	if sheet.views == nil {
+		sheet.views = make([]*Image, len(sheet.Sprites))
+	}
+	if sheet.views[i] == nil {
+		sheet.views[i], err = sheet.Image.SubImage(sheet.Sprites[i].Rect)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return sheet.views[i], nil
+}
+
+// Index returns the index of the sprite with the provided name, and a boolean
+// indicating whether such a sprite exists.
+func (sheet *SpriteSheet) Index(name string) (i int, ok bool) {
+	for i, sprite := range sheet.Sprites {
+		if sprite.Name == name {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// sheetDesc is a parsed JSON sprite sheet description.
+type sheetDesc struct {
+	// The path of the sprite sheet image.
+	imgPath string
+	// The sprites of the sprite sheet.
+	sprites []Sprite
+}
+
+// jsonFrame is a frame of the JSON sprite sheet formats of Aseprite and
+// TexturePacker.
+type jsonFrame struct {
+	Filename string `json:"filename"`
+	Frame    struct {
+		X int `json:"x"`
+		Y int `json:"y"`
+		W int `json:"w"`
+		H int `json:"h"`
+	} `json:"frame"`
+	Rotated bool `json:"rotated"`
+	Trimmed bool `json:"trimmed"`
+	// The location of the trimmed frame within the original frame.
+	SpriteSourceSize struct {
+		X int `json:"x"`
+		Y int `json:"y"`
+	} `json:"spriteSourceSize"`
+	// The dimensions of the original frame.
+	SourceSize struct {
+		W int `json:"w"`
+		H int `json:"h"`
+	} `json:"sourceSize"`
+	Duration int `json:"duration"`
+}
+
+// parseSheet parses a JSON sprite sheet description in the format of Aseprite
+// or TexturePacker. The frames are stored either as an array, or as an object
+// keyed by file name, in which case the order of the keys is preserved.
+func parseSheet(r io.Reader) (desc *sheetDesc, err error) {
+	var raw struct {
+		Frames json.RawMessage `json:"frames"`
+		Meta   struct {
+			Image string `json:"image"`
+		} `json:"meta"`
+	}
+	err = json.NewDecoder(r).Decode(&raw)
+	if err != nil {
+		return nil, err
+	}
+	if len(raw.Frames) == 0 {
+		return nil, errors.New("win.parseSheet: missing frames")
+	}
+	var frames []jsonFrame
+	if raw.Frames[0] == '[' {
+		err = json.Unmarshal(raw.Frames, &frames)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		frames, err = parseFrameHash(raw.Frames)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	desc = &sheetDesc{imgPath: raw.Meta.Image}
+	for _, frame := range frames {
+		if frame.Rotated {
+			return nil, fmt.Errorf("win.parseSheet: rotated sprite %q not supported", frame.Filename)
+		}
+		f := frame.Frame
+		sprite := Sprite{
+			Name:     frame.Filename,
+			Rect:     image.Rect(f.X, f.Y, f.X+f.W, f.Y+f.H),
+			Size:     image.Pt(f.W, f.H),
+			Duration: time.Duration(frame.Duration) * time.Millisecond,
+		}
+		if frame.Trimmed {
+			if frame.SourceSize.W < f.W || frame.SourceSize.H < f.H {
+				return nil, fmt.Errorf("win.parseSheet: invalid source size of trimmed sprite %q", frame.Filename)
+			}
+			sprite.Offset = image.Pt(frame.SpriteSourceSize.X, frame.SpriteSourceSize.Y)
+			sprite.Size = image.Pt(frame.SourceSize.W, frame.SourceSize.H)
+		}
+		desc.sprites = append(desc.sprites, sprite)
+	}
+	return desc, nil
+}
+
+// parseFrameHash parses a JSON object of frames keyed by file name, preserving
+// the order of the keys.
+func parseFrameHash(data []byte) (frames []jsonFrame, err error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	// Opening brace of the object.
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	if tok != json.Delim('{') {
+		return nil, fmt.Errorf("win.parseFrameHash: invalid frames %v; expected array or object", tok)
+	}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		name, ok := tok.(string)
+		if !ok {
+			return nil, fmt.Errorf("win.parseFrameHash: invalid frame name %v", tok)
+		}
+		var frame jsonFrame
+		err = dec.Decode(&frame)
+		if err != nil {
+			return nil, err
+		}
+		frame.Filename = name
+		frames = append(frames, frame)
+	}
+	return frames, nil
+}
@@ -0,0 +1,124 @@
+package win
+
+import (
+	"image"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewSpriteSheet(t *testing.T) {
+	img := newTestImage(t, image.NewNRGBA(image.Rect(0, 0, 10, 8)))
+	sheet, err := NewSpriteSheet(img, 4, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sheet.Free()
+	// Partial sprites at the right edge are ignored.
+	want := []Sprite{
+		{Rect: image.Rect(0, 0, 4, 4), Size: image.Pt(4, 4)},
+		{Rect: image.Rect(4, 0, 8, 4), Size: image.Pt(4, 4)},
+		{Rect: image.Rect(0, 4, 4, 8), Size: image.Pt(4, 4)},
+		{Rect: image.Rect(4, 4, 8, 8), Size: image.Pt(4, 4)},
+	}
+	if !reflect.DeepEqual(sheet.Sprites, want) {
+		t.Errorf("sprites mismatch; expected %v, got %v", want, sheet.Sprites)
+	}
+	sprite, err := sheet.Sprite(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sprite.Width != 4 || sprite.Height != 4 {
+		t.Errorf("sprite dimensions mismatch; expected 4x4, got %dx%d", sprite.Width, sprite.Height)
+	}
+	if _, err := sheet.Sprite(4); err == nil {
+		t.Error("sprite index out of range; expected error, got nil")
+	}
+	if _, err := NewSpriteSheet(img, 0, 4); err == nil {
+		t.Error("invalid sprite dimensions; expected error, got nil")
+	}
+}
+
+func TestReadSpriteSheet(t *testing.T) {
+	aseprite := []Sprite{
+		{Name: "idle.aseprite", Rect: image.Rect(0, 0, 4, 4), Size: image.Pt(4, 4), Duration: 100 * time.Millisecond},
+		{Name: "attack.aseprite", Rect: image.Rect(4, 0, 8, 4), Size: image.Pt(4, 4), Duration: 150 * time.Millisecond},
+	}
+	golden := []struct {
+		path string
+		want []Sprite
+	}{
+		// The order of the keys of frame hashes is preserved.
+		{path: "testdata/aseprite_hash.json", want: aseprite},
+		{path: "testdata/aseprite_array.json", want: aseprite},
+		// Trimmed sprites keep their offset within the original frame.
+		{
+			path: "testdata/texturepacker.json",
+			want: []Sprite{
+				{Name: "run_0.png", Rect: image.Rect(0, 0, 3, 4), Offset: image.Pt(1, 2), Size: image.Pt(6, 8)},
+				{Name: "run_1.png", Rect: image.Rect(3, 0, 9, 8), Size: image.Pt(6, 8)},
+			},
+		},
+	}
+	for _, g := range golden {
+		f, err := os.Open(g.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sheet, err := ReadSpriteSheet(nil, f)
+		f.Close()
+		if err != nil {
+			t.Errorf("%s: %v", g.path, err)
+			continue
+		}
+		if !reflect.DeepEqual(sheet.Sprites, g.want) {
+			t.Errorf("%s: sprites mismatch; expected %v, got %v", g.path, g.want, sheet.Sprites)
+		}
+		if i, ok := sheet.Index(g.want[1].Name); !ok || i != 1 {
+			t.Errorf("%s: index of %q mismatch; expected 1, got %d (ok=%v)", g.path, g.want[1].Name, i, ok)
+		}
+	}
+}
+
+func TestReadSpriteSheetInvalid(t *testing.T) {
+	golden := []struct {
+		name string
+		data string
+	}{
+		{name: "missing frames", data: `{"meta": {}}`},
+		{name: "null frames", data: `{"frames": null}`},
+		{name: "string frames", data: `{"frames": "a.png"}`},
+		{name: "number frame name", data: `{"frames": {"a": {}, 1: {}}}`},
+		{name: "rotated", data: `{"frames": [{"filename": "a.png", "rotated": true}]}`},
+		{name: "trimmed without source size", data: `{"frames": [{"frame": {"w": 2, "h": 2}, "trimmed": true}]}`},
+		{name: "truncated", data: `{"frames": {"a.png": {"frame": `},
+	}
+	for _, g := range golden {
+		if _, err := ReadSpriteSheet(nil, strings.NewReader(g.data)); err == nil {
+			t.Errorf("%s: expected error, got nil", g.name)
+		}
+	}
+}
+
+func TestLoadSpriteSheet(t *testing.T) {
+	sheet, err := LoadSpriteSheet("testdata/texturepacker.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sheet.Free()
+	if sheet.Image.Width != 10 || sheet.Image.Height != 8 {
+		t.Errorf("image dimensions mismatch; expected 10x8, got %dx%d", sheet.Image.Width, sheet.Image.Height)
+	}
+	sprite, err := sheet.Sprite(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sprite.Width != 3 || sprite.Height != 4 {
+		t.Errorf("sprite dimensions mismatch; expected 3x4, got %dx%d", sprite.Width, sprite.Height)
+	}
+	if _, err := LoadSpriteSheet("testdata/missing.json"); err == nil {
+		t.Error("missing sprite sheet; expected error, got nil")
+	}
+}
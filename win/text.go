@@ -0,0 +1,57 @@
+package win
+
+// #cgo pkg-config: sdl2
+// #include <SDL2/SDL.h>
+import "C"
+
+import (
+	"image"
+)
+
+// TextInput is a text input event which is triggered when text has been
+// entered, either directly from the keyboard or by committing the composition
+// of an input method editor (IME).
+type TextInput struct {
+	// The entered UTF-8 encoded text, which may consist of several runes.
+	Text string
+}
+
+// TextEdit is a text composition event which is triggered when the
+// composition text of an input method editor (IME) has changed. The
+// composition text should be displayed at the text cursor, but not inserted
+// until a TextInput event is received.
+type TextEdit struct {
+	// The UTF-8 encoded composition text.
+	Text string
+	// The position of the composition cursor in runes, counted from the start
+	// of Text.
+	Cursor int
+	// The length of the selection in runes, counted from Cursor.
+	Selection int
+}
+
+// StartTextInput starts accepting text input, which enables TextInput and
+// TextEdit events. On some platforms it also shows the on-screen keyboard or
+// activates the input method editor.
+func StartTextInput() {
+	C.SDL_StartTextInput()
+}
+
+// StopTextInput stops accepting text input, which disables TextInput and
+// TextEdit events.
+func StopTextInput() {
+	C.SDL_StopTextInput()
+}
+
+// IsTextInputActive returns true if text input is being accepted, and false
+// otherwise.
+func IsTextInputActive() bool {
+	return C.SDL_IsTextInputActive() == C.SDL_TRUE
+}
+
+// SetTextInputRect sets the rectangle, in window coordinates, of the text
+// field which receives text input. It is used to position the candidate window
+// of the input method editor close to the text field.
+func SetTextInputRect(r image.Rectangle) {
+	C.SDL_SetTextInputRect(cRect(r))
+}
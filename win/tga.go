@@ -0,0 +1,256 @@
+package win
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+)
+
+// TGA image types.
+const (
+	tgaColorMapped    = 1
+	tgaTrueColor      = 2
+	tgaGrayscale      = 3
+	tgaRLEColorMapped = 9
+	tgaRLETrueColor   = 10
+	tgaRLEGrayscale   = 11
+)
+
+func init() {
+	// TGA image files have no signature, so the color map type and image type
+	// of the header are used to detect them. The first byte is the length of
+	// the image ID.
+	for _, magic := range []string{
+		"?\x00\x02", "?\x00\x03", "?\x00\x0A", "?\x00\x0B",
+		"?\x01\x01", "?\x01\x09",
+	} {
+		image.RegisterFormat("tga", magic, decodeTGA, decodeTGAConfig)
+	}
+}
+
+// tgaHeader is the header of TGA image files.
+type tgaHeader struct {
+	IDLength     uint8
+	ColorMapType uint8
+	ImageType    uint8
+	// Color map specification.
+	ColorMapFirst     uint16
+	ColorMapLength    uint16
+	ColorMapEntrySize uint8
+	// Image specification.
+	XOrigin    uint16
+	YOrigin    uint16
+	Width      uint16
+	Height     uint16
+	PixelDepth uint8
+	// Descriptor holds the number of alpha bits per pixel in bits 0-3, and the
+	// pixel order in bits 4 (right-to-left) and 5 (top-to-bottom).
+	Descriptor uint8
+}
+
+// readTGAHeader reads and validates the header of a TGA image.
+func readTGAHeader(r io.Reader) (hdr *tgaHeader, err error) {
+	hdr = new(tgaHeader)
+	err = binary.Read(r, binary.LittleEndian, hdr)
+	if err != nil {
+		return nil, err
+	}
+	var depths []uint8
+	switch hdr.ImageType {
+	case tgaColorMapped, tgaRLEColorMapped:
+		if hdr.ColorMapType != 1 {
+			return nil, errors.New("win.readTGAHeader: missing color map of color-mapped TGA image")
+		}
+		depths = []uint8{8, 16}
+	case tgaTrueColor, tgaRLETrueColor:
+		depths = []uint8{15, 16, 24, 32}
+	case tgaGrayscale, tgaRLEGrayscale:
+		depths = []uint8{8, 16}
+	default:
+		return nil, fmt.Errorf("win.readTGAHeader: unsupported TGA image type %d", hdr.ImageType)
+	}
+	if !containsDepth(depths, hdr.PixelDepth) {
+		return nil, fmt.Errorf("win.readTGAHeader: unsupported pixel depth %d of TGA image type %d", hdr.PixelDepth, hdr.ImageType)
+	}
+	if hdr.ColorMapType == 1 && !containsDepth([]uint8{15, 16, 24, 32}, hdr.ColorMapEntrySize) {
+		return nil, fmt.Errorf("win.readTGAHeader: unsupported TGA color map entry size %d", hdr.ColorMapEntrySize)
+	}
+	if int(hdr.Width)*int(hdr.Height) > maxDecodePixels {
+		return nil, errors.New("win.readTGAHeader: invalid TGA image dimensions")
+	}
+	return hdr, nil
+}
+
+// containsDepth reports whether depths contains depth.
+func containsDepth(depths []uint8, depth uint8) bool {
+	for _, d := range depths {
+		if d == depth {
+			return true
+		}
+	}
+	return false
+}
+
+// decodeTGAConfig returns the color model and dimensions of the TGA image read
+// from r.
+func decodeTGAConfig(r io.Reader) (image.Config, error) {
+	hdr, err := readTGAHeader(r)
+	if err != nil {
+		return image.Config{}, err
+	}
+	config := image.Config{
+		ColorModel: color.NRGBAModel,
+		Width:      int(hdr.Width),
+		Height:     int(hdr.Height),
+	}
+	return config, nil
+}
+
+// decodeTGA decodes the TGA image read from r.
+func decodeTGA(r io.Reader) (image.Image, error) {
+	br := bufio.NewReader(r)
+	hdr, err := readTGAHeader(br)
+	if err != nil {
+		return nil, err
+	}
+	// Skip the image ID.
+	if _, err := br.Discard(int(hdr.IDLength)); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	alpha := hdr.Descriptor&0x0F != 0
+
+	// Read the color map.
+	var colorMap []color.NRGBA
+	if hdr.ColorMapType == 1 {
+		size := (int(hdr.ColorMapEntrySize) + 7) / 8
+		buf := make([]byte, int(hdr.ColorMapLength)*size)
+		if _, err := io.ReadFull(br, buf); err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		colorMap = make([]color.NRGBA, hdr.ColorMapLength)
+		for i := range colorMap {
+			colorMap[i] = tgaColor(buf[i*size:], hdr.ColorMapEntrySize, alpha)
+		}
+	}
+
+	// Read the pixels, which are stored in packets of repeated or raw pixels
+	// in run-length encoded images. Packets may span several lines.
+	width, height := int(hdr.Width), int(hdr.Height)
+	size := (int(hdr.PixelDepth) + 7) / 8
+	var data []byte
+	switch hdr.ImageType {
+	case tgaRLEColorMapped, tgaRLETrueColor, tgaRLEGrayscale:
+		data = make([]byte, width*height*size)
+		for i := 0; i < len(data); {
+			packet, err := br.ReadByte()
+			if err != nil {
+				return nil, unexpectedEOF(err)
+			}
+			n := int(packet&0x7F) + 1
+			if i+n*size > len(data) {
+				return nil, errors.New("win.decodeTGA: run-length encoded packet exceeds image size")
+			}
+			if packet&0x80 != 0 {
+				// Repeated pixel.
+				if _, err := io.ReadFull(br, data[i:i+size]); err != nil {
+					return nil, unexpectedEOF(err)
+				}
+				for j := 1; j < n; j++ {
+					copy(data[i+j*size:], data[i:i+size])
+				}
+			} else {
+				// Raw pixels.
+				if _, err := io.ReadFull(br, data[i:i+n*size]); err != nil {
+					return nil, unexpectedEOF(err)
+				}
+			}
+			i += n * size
+		}
+	default:
+		// The buffer grows as the pixels are read, so that truncated images
+		// fail before the entire image is allocated.
+		n := int64(width * height * size)
+		data, err = io.ReadAll(io.LimitReader(br, n))
+		if err != nil {
+			return nil, err
+		}
+		if int64(len(data)) < n {
+			return nil, io.ErrUnexpectedEOF
+		}
+	}
+
+	// Convert the pixels, which are stored bottom-to-top and left-to-right
+	// unless specified otherwise by the descriptor.
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	rightToLeft := hdr.Descriptor&0x10 != 0
+	topToBottom := hdr.Descriptor&0x20 != 0
+	for y := 0; y < height; y++ {
+		dy := height - 1 - y
+		if topToBottom {
+			dy = y
+		}
+		for x := 0; x < width; x++ {
+			dx := x
+			if rightToLeft {
+				dx = width - 1 - x
+			}
+			buf := data[(y*width+x)*size:]
+			var c color.NRGBA
+			switch hdr.ImageType {
+			case tgaColorMapped, tgaRLEColorMapped:
+				i := int(buf[0])
+				if size == 2 {
+					i |= int(buf[1]) << 8
+				}
+				i -= int(hdr.ColorMapFirst)
+				if i < 0 || i >= len(colorMap) {
+					return nil, fmt.Errorf("win.decodeTGA: color map index %d out of range", i)
+				}
+				c = colorMap[i]
+			case tgaGrayscale, tgaRLEGrayscale:
+				// 16-bit grayscale pixels store the alpha channel in the second
+				// byte.
+				c = color.NRGBA{R: buf[0], G: buf[0], B: buf[0], A: 0xFF}
+				if size == 2 && alpha {
+					c.A = buf[1]
+				}
+			default:
+				c = tgaColor(buf, hdr.PixelDepth, alpha)
+			}
+			img.SetNRGBA(dx, dy, c)
+		}
+	}
+	return img, nil
+}
+
+// tgaColor returns the color of the true-color pixel of the provided depth
+// stored at the start of buf. The alpha channel is used only if alpha is true.
+func tgaColor(buf []byte, depth uint8, alpha bool) color.NRGBA {
+	switch depth {
+	case 15, 16:
+		// ARRRRRGG GGGBBBBB, in little endian.
+		v := uint16(buf[0]) | uint16(buf[1])<<8
+		c := color.NRGBA{
+			R: expand5(int(v>>10) & 0x1F),
+			G: expand5(int(v>>5) & 0x1F),
+			B: expand5(int(v) & 0x1F),
+			A: 0xFF,
+		}
+		if depth == 16 && alpha && v&0x8000 == 0 {
+			c.A = 0
+		}
+		return c
+	case 24:
+		return color.NRGBA{R: buf[2], G: buf[1], B: buf[0], A: 0xFF}
+	default:
+		c := color.NRGBA{R: buf[2], G: buf[1], B: buf[0], A: 0xFF}
+		if alpha {
+			c.A = buf[3]
+		}
+		return c
+	}
+}
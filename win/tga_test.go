@@ -0,0 +1,110 @@
+package win
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+// decodeFile decodes the provided image file, and reports an error unless it
+// is of the provided format.
+func decodeFile(t *testing.T, path, format string) image.Image {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	img, kind, err := image.Decode(f)
+	if err != nil {
+		t.Fatalf("%s: %v", path, err)
+	}
+	if kind != format {
+		t.Errorf("%s: format mismatch; expected %q, got %q", path, format, kind)
+	}
+	return img
+}
+
+func TestDecodeTGA(t *testing.T) {
+	// The TGA test images, generated by an independent encoder, hold the
+	// following colors; alpha is given by the alpha function of each image.
+	r := color.NRGBA{R: 0xFF, A: 0xFF}
+	g := color.NRGBA{G: 0xFF, A: 0xFF}
+	b := color.NRGBA{B: 0xFF, A: 0xFF}
+	w := color.NRGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF}
+	k := color.NRGBA{A: 0xFF}
+	y := color.NRGBA{R: 0xFF, G: 0xFF, A: 0xFF}
+	c := color.NRGBA{G: 0xFF, B: 0xFF, A: 0xFF}
+	m := color.NRGBA{R: 0xFF, B: 0xFF, A: 0xFF}
+	rows := [][]color.NRGBA{
+		{r, g, b, w},
+		{k, k, k, y},
+		{y, y, c, m},
+	}
+	opaque := func(x, y int, c color.NRGBA) uint8 { return 0xFF }
+	// Black pixels are transparent.
+	keyed := func(x, y int, c color.NRGBA) uint8 {
+		if c == k {
+			return 0
+		}
+		return 0xFF
+	}
+	golden := []struct {
+		name  string
+		alpha func(x, y int, c color.NRGBA) uint8
+	}{
+		// Run-length encoded 24-bit true-color, top-to-bottom, with an image
+		// ID and a run spanning two lines.
+		{name: "rle_truecolor.tga", alpha: opaque},
+		// 8-bit color-mapped with a 24-bit color map starting at index 2,
+		// bottom-to-top.
+		{name: "colormapped.tga", alpha: opaque},
+		// Run-length encoded 8-bit color-mapped with a 32-bit color map,
+		// top-to-bottom.
+		{name: "rle_colormapped.tga", alpha: keyed},
+		// 16-bit true-color with a 1-bit alpha channel, top-to-bottom.
+		{name: "16bit.tga", alpha: keyed},
+		// 32-bit true-color, right-to-left and bottom-to-top.
+		{name: "right_to_left.tga", alpha: func(x, y int, c color.NRGBA) uint8 { return uint8((x*64 + y*80) % 256) }},
+	}
+	for _, gold := range golden {
+		img := decodeFile(t, filepath.Join("testdata", gold.name), "tga")
+		want := image.NewNRGBA(image.Rect(0, 0, 4, 3))
+		for y, row := range rows {
+			for x, c := range row {
+				c.A = gold.alpha(x, y, c)
+				want.SetNRGBA(x, y, c)
+			}
+		}
+		if !reflect.DeepEqual(img, want) {
+			t.Errorf("%s: image mismatch; expected %v, got %v", gold.name, want.Pix, img.(*image.NRGBA).Pix)
+		}
+	}
+}
+
+func TestDecodeTGAHuge(t *testing.T) {
+	// A 65535x65535 true-color image exceeds the size limit, and must be
+	// rejected before its pixels are allocated.
+	hdr := []byte{0, 0, tgaTrueColor, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0xFF, 0xFF, 0xFF, 0xFF, 32, 0x28}
+	if _, err := decodeTGA(bytes.NewReader(hdr)); err == nil {
+		t.Error("huge TGA image; expected error, got nil")
+	}
+	// A truncated 16384x16384 true-color image is within the size limit, but
+	// must fail without allocating its pixels.
+	hdr = []byte{0, 0, tgaTrueColor, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0x00, 0x40, 0x00, 0x40, 32, 0x28}
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	if _, err := decodeTGA(bytes.NewReader(hdr)); err != io.ErrUnexpectedEOF {
+		t.Errorf("truncated TGA image; expected %v, got %v", io.ErrUnexpectedEOF, err)
+	}
+	runtime.ReadMemStats(&after)
+	if n := after.TotalAlloc - before.TotalAlloc; n > 1<<20 {
+		t.Errorf("truncated TGA image; expected less than 1 MiB allocated, got %d bytes", n)
+	}
+}
@@ -0,0 +1,86 @@
+package win
+
+// #cgo pkg-config: sdl2
+// #include <SDL2/SDL.h>
+import "C"
+
+import (
+	"image"
+)
+
+// FingerDown is a touch event which is triggered when a finger touches the
+// touch device.
+type FingerDown struct {
+	// The touch device and finger of the event.
+	TouchID, FingerID int64
+	// The location of the finger in window coordinates.
+	Point image.Point
+	// The pressure of the finger, in the range from 0.0 to 1.0.
+	Pressure float64
+}
+
+// FingerUp is a touch event which is triggered when a finger is lifted from
+// the touch device.
+type FingerUp struct {
+	// The touch device and finger of the event.
+	TouchID, FingerID int64
+	// The location of the finger in window coordinates.
+	Point image.Point
+	// The pressure of the finger, in the range from 0.0 to 1.0.
+	Pressure float64
+}
+
+// FingerMove is a touch event which is triggered when a finger is moved on the
+// touch device.
+type FingerMove struct {
+	// The touch device and finger of the event.
+	TouchID, FingerID int64
+	// The location of the finger in window coordinates.
+	Point image.Point
+	// The relative motion of the finger in window coordinates. The motion is
+	// fractional, as slow motion may move less than a pixel per event.
+	DX, DY float64
+	// The pressure of the finger, in the range from 0.0 to 1.0.
+	Pressure float64
+}
+
+// MultiGesture is a touch event which is triggered when several fingers move
+// at once, e.g. to pinch or rotate.
+type MultiGesture struct {
+	// The touch device of the event.
+	TouchID int64
+	// The center of the gesture in window coordinates.
+	Center image.Point
+	// The rotation of the gesture in radians, since the previous event.
+	Rotation float64
+	// The change of distance between the fingers, since the previous event;
+	// normalized to the size of the touch device.
+	Pinch float64
+	// The number of fingers involved in the gesture.
+	Fingers int
+}
+
+// SetTouchMouseEvents enables or disables the emulation of mouse events from
+// touch events. It is enabled by default, in which case touch input is also
+// reported through mouse events.
+func SetTouchMouseEvents(enabled bool) {
+	value := "0"
+	if enabled {
+		value = "1"
+	}
+	setHint(C.SDL_HINT_TOUCH_MOUSE_EVENTS, value)
+}
+
+// touchDelta converts normalized relative touch motion to relative motion in
+// window coordinates.
+func touchDelta(dx, dy C.float) (float64, float64) {
+	width, height := Size()
+	return float64(dx) * float64(width), float64(dy) * float64(height)
+}
+
+// touchPoint converts normalized touch coordinates, in the range from 0.0 to
+// 1.0, to window coordinates.
+func touchPoint(x, y C.float) image.Point {
+	width, height := Size()
+	return image.Pt(int(float64(x)*float64(width)), int(float64(y)*float64(height)))
+}
@@ -0,0 +1,34 @@
+package win
+
+import (
+	"image"
+	"reflect"
+	"testing"
+)
+
+func TestTouchEvents(t *testing.T) {
+	openHeadless(t, 200, 100)
+
+	// The pushed values are exactly representable as float32, so that the
+	// normalized touch coordinates convert to exact window coordinates.
+	if err := pushFingerDown(1, 2, 0.25, 0.5, 0.75); err != nil {
+		t.Fatal(err)
+	}
+	if err := pushFingerMove(1, 2, 0.375, 0.625, 0.0025, -0.125, 0.5); err != nil {
+		t.Fatal(err)
+	}
+	if err := pushMultiGesture(1, 0.5, 0.75, -0.5, 0.25, 2); err != nil {
+		t.Fatal(err)
+	}
+	want := []interface{}{
+		FingerDown{TouchID: 1, FingerID: 2, Point: image.Pt(50, 50), Pressure: 0.75},
+		// A slow motion of less than a pixel must not be truncated.
+		FingerMove{TouchID: 1, FingerID: 2, Point: image.Pt(75, 62), DX: float64(float32(0.0025)) * 200, DY: -12.5, Pressure: 0.5},
+		MultiGesture{TouchID: 1, Center: image.Pt(100, 75), Rotation: -0.5, Pinch: 0.25, Fingers: 2},
+	}
+	for i, w := range want {
+		if event := PollEvent(); !reflect.DeepEqual(event, w) {
+			t.Errorf("event %d: mismatch; expected %#v, got %#v", i, w, event)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package win
+
+// #cgo pkg-config: sdl2
+// #include <SDL2/SDL.h>
+import "C"
+
+import (
+	"image"
+	"math"
+
+	xdraw "golang.org/x/image/draw"
+	"golang.org/x/image/math/f64"
+)
+
+// Flip returns a copy of the image which is mirrored horizontally, vertically
+// or both. The pixel format, blend mode, color key, and alpha and color
+// modulation of the image are preserved.
+//
+// Note: The Free method of the returned image should be called when finished
+// using it.
+func (img *Image) Flip(horizontal, vertical bool) (flipped *Image, err error) {
+	s := C.SDL_ConvertSurface(img.s, img.s.format, 0)
+	if s == nil {
+		return nil, getError()
+	}
+	flipped = &Image{
+		Width:  int(s.w),
+		Height: int(s.h),
+		s:      s,
+	}
+	// SDL_ConvertSurface resets the blend mode of the copy.
+	if err := flipped.inherit(img); err != nil {
+		flipped.Free()
+		return nil, err
+	}
+	if C.SDL_LockSurface(s) != 0 {
+		flipped.Free()
+		return nil, getError()
+	}
+	defer C.SDL_UnlockSurface(s)
+
+	bpp := int(s.format.BytesPerPixel)
+	pitch := int(s.pitch)
+	width, height := flipped.Width, flipped.Height
+	pix := surfacePix(s.pixels, pitch*height)
+	if horizontal {
+		// Reverse the pixels of each line.
+		tmp := make([]byte, bpp)
+		for y := 0; y < height; y++ {
+			line := pix[y*pitch : y*pitch+width*bpp]
+			for l, r := 0, (width-1)*bpp; l < r; l, r = l+bpp, r-bpp {
+				copy(tmp, line[l:l+bpp])
+				copy(line[l:l+bpp], line[r:r+bpp])
+				copy(line[r:r+bpp], tmp)
+			}
+		}
+	}
+	if vertical {
+		// Swap the lines of the top and bottom halves.
+		tmp := make([]byte, width*bpp)
+		for t, b := 0, height-1; t < b; t, b = t+1, b-1 {
+			top := pix[t*pitch : t*pitch+width*bpp]
+			bottom := pix[b*pitch : b*pitch+width*bpp]
+			copy(tmp, top)
+			copy(top, bottom)
+			copy(bottom, tmp)
+		}
+	}
+	return flipped, nil
+}
+
+// Rotate returns a copy of the image rotated clockwise by the provided angle in
+// degrees around the pivot point, using the provided interpolation filter. The
+// returned image is large enough to hold the entire rotated image, and areas
+// not covered by it are transparent.
+//
+// The rotated pivot point is the location of the pivot point within the
+// returned image. Drawing the returned image at dp.Sub(rpivot) places the
+// pivot point at dp, just like drawing the original image at dp.Sub(pivot).
+//
+// Contrary to Flip, the returned image has a 32-bit pixel format, with an alpha
+// channel if needed. It inherits the blend mode, and alpha and color
+// modulation of the image, and its color key is converted to transparent
+// pixels.
+//
+// Note: The Free method of the returned image should be called when finished
+// using it.
+func (img *Image) Rotate(angle float64, pivot image.Point, filter Filter) (rotated *Image, rpivot image.Point, err error) {
+	interp, err := filter.interpolator()
+	if err != nil {
+		return nil, image.ZP, err
+	}
+	src, err := surfaceNRGBA(img.s)
+	if err != nil {
+		return nil, image.ZP, err
+	}
+
+	// Rotate the corners of the image around the pivot point to determine the
+	// bounds of the rotated image.
+	sin, cos := math.Sincos(angle * math.Pi / 180)
+	// Snap values to avoid off-by-one dimensions caused by rounding errors of
+	// right angles.
+	sin, cos = snap(sin), snap(cos)
+	rot := func(x, y float64) (float64, float64) {
+		x -= float64(pivot.X)
+		y -= float64(pivot.Y)
+		return cos*x - sin*y, sin*x + cos*y
+	}
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, corner := range [][2]float64{{0, 0}, {float64(img.Width), 0}, {0, float64(img.Height)}, {float64(img.Width), float64(img.Height)}} {
+		x, y := rot(corner[0], corner[1])
+		minX, minY = math.Min(minX, x), math.Min(minY, y)
+		maxX, maxY = math.Max(maxX, x), math.Max(maxY, y)
+	}
+	minX, minY = math.Floor(snap(minX)), math.Floor(snap(minY))
+	maxX, maxY = math.Ceil(snap(maxX)), math.Ceil(snap(maxY))
+	rpivot = image.Pt(int(-minX), int(-minY))
+
+	// s2d maps source coordinates to destination coordinates.
+	tx, ty := rot(0, 0)
+	s2d := f64.Aff3{
+		cos, -sin, tx - minX,
+		sin, cos, ty - minY,
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, int(maxX-minX), int(maxY-minY)))
+	interp.Transform(dst, s2d, src, src.Bounds(), xdraw.Src, nil)
+	rotated, err = ReadImage(dst)
+	if err != nil {
+		return nil, image.ZP, err
+	}
+	if err := rotated.inheritConverted(img); err != nil {
+		rotated.Free()
+		return nil, image.ZP, err
+	}
+	return rotated, rpivot, nil
+}
+
+// snap rounds v to the nearest integer if it is within rounding error of it.
+func snap(v float64) float64 {
+	if r := math.Round(v); math.Abs(v-r) < 1e-9 {
+		return r
+	}
+	return v
+}
@@ -0,0 +1,170 @@
+package win
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"reflect"
+	"testing"
+)
+
+// transformSrc returns an asymmetric opaque test image of the specified
+// dimensions, in which each pixel has a distinct color.
+func transformSrc(width, height int) *image.NRGBA {
+	src := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			src.SetNRGBA(x, y, color.NRGBA{R: uint8(x * 40), G: uint8(y * 40), B: 0x80, A: 0xFF})
+		}
+	}
+	return src
+}
+
+// checkTransform reports an error unless got has the specified dimensions and
+// each of its pixels (x, y) equals the pixel at(x, y) of src.
+func checkTransform(t *testing.T, name string, got, src *image.NRGBA, width, height int, at func(x, y int) (int, int)) {
+	t.Helper()
+	if size := got.Bounds().Size(); size != image.Pt(width, height) {
+		t.Errorf("%s: size mismatch; expected %v, got %v", name, image.Pt(width, height), size)
+		return
+	}
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			sx, sy := at(x, y)
+			if want, c := src.NRGBAAt(sx, sy), got.NRGBAAt(x, y); c != want {
+				t.Errorf("%s: pixel (%d, %d) mismatch; expected %v, got %v", name, x, y, want, c)
+			}
+		}
+	}
+}
+
+func TestFlip(t *testing.T) {
+	openHeadless(t, 16, 16)
+	const w, h = 3, 2
+	src := transformSrc(w, h)
+	img := newTestImage(t, src)
+	golden := []struct {
+		name                 string
+		horizontal, vertical bool
+		at                   func(x, y int) (int, int)
+	}{
+		{name: "H", horizontal: true, at: func(x, y int) (int, int) { return w - 1 - x, y }},
+		{name: "V", vertical: true, at: func(x, y int) (int, int) { return x, h - 1 - y }},
+		{name: "HV", horizontal: true, vertical: true, at: func(x, y int) (int, int) { return w - 1 - x, h - 1 - y }},
+	}
+	for _, g := range golden {
+		flipped, err := img.Flip(g.horizontal, g.vertical)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if flipped.s.format.format != img.s.format.format {
+			t.Errorf("%s: pixel format not preserved", g.name)
+		}
+		checkTransform(t, g.name, toNRGBA(t, flipped), src, w, h, g.at)
+		flipped.Free()
+	}
+
+	// The drawing state is preserved; flipping twice gives an image which
+	// draws like the original.
+	src.SetNRGBA(0, 0, color.NRGBA{R: 0xFF, G: 0x80, A: 0x80})
+	for _, mode := range []BlendMode{BlendAdd, BlendNone} {
+		img := newTestImage(t, src)
+		if err := img.SetBlendMode(mode); err != nil {
+			t.Fatal(err)
+		}
+		if err := img.SetAlphaMod(0x80); err != nil {
+			t.Fatal(err)
+		}
+		tmp, err := img.Flip(true, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		flipped, err := tmp.Flip(true, true)
+		tmp.Free()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var results []*image.NRGBA
+		for _, sprite := range []*Image{img, flipped} {
+			dst := newTestImage(t, image.NewNRGBA(image.Rect(0, 0, w, h)))
+			if err := dst.Fill(color.NRGBA{R: 0x30, G: 0x30, B: 0x30, A: 0xFF}); err != nil {
+				t.Fatal(err)
+			}
+			if err := dst.Draw(image.ZP, sprite); err != nil {
+				t.Fatal(err)
+			}
+			results = append(results, toNRGBA(t, dst))
+		}
+		flipped.Free()
+		if !reflect.DeepEqual(results[0].Pix, results[1].Pix) {
+			t.Errorf("blend mode %d: pixels mismatch; expected %v, got %v", mode, results[0].Pix, results[1].Pix)
+		}
+	}
+}
+
+func TestRotate(t *testing.T) {
+	openHeadless(t, 16, 16)
+	const w, h = 3, 2
+	src := transformSrc(w, h)
+	img := newTestImage(t, src)
+	pivot := image.Pt(1, 0)
+	golden := []struct {
+		angle         float64
+		width, height int
+		rpivot        image.Point
+		at            func(x, y int) (int, int)
+	}{
+		{angle: 90, width: h, height: w, rpivot: image.Pt(h-pivot.Y, pivot.X), at: func(x, y int) (int, int) { return y, h - 1 - x }},
+		{angle: 180, width: w, height: h, rpivot: image.Pt(w-pivot.X, h-pivot.Y), at: func(x, y int) (int, int) { return w - 1 - x, h - 1 - y }},
+		{angle: 270, width: h, height: w, rpivot: image.Pt(pivot.Y, w-pivot.X), at: func(x, y int) (int, int) { return w - 1 - y, x }},
+	}
+	for _, g := range golden {
+		rotated, rpivot, err := img.Rotate(g.angle, pivot, NearestNeighbor)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rpivot != g.rpivot {
+			t.Errorf("%v°: rotated pivot mismatch; expected %v, got %v", g.angle, g.rpivot, rpivot)
+		}
+		checkTransform(t, fmt.Sprintf("%v°", g.angle), toNRGBA(t, rotated), src, g.width, g.height, g.at)
+		rotated.Free()
+	}
+}
+
+func TestRotateColorKey(t *testing.T) {
+	openHeadless(t, 16, 16)
+	src := transformSrc(3, 2)
+	key := src.NRGBAAt(0, 0)
+	img := newTestImage(t, src)
+	if err := img.SetColorKey(key); err != nil {
+		t.Fatal(err)
+	}
+	if err := img.SetBlendMode(BlendAdd); err != nil {
+		t.Fatal(err)
+	}
+	rotated, _, err := img.Rotate(180, image.ZP, NearestNeighbor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rotated.Free()
+
+	// Draw the rotated image additively onto a gray background; the color
+	// keyed pixel, now at (2, 1), must leave the background unchanged.
+	bg := color.NRGBA{R: 0x10, G: 0x10, B: 0x10, A: 0xFF}
+	dst := newTestImage(t, image.NewNRGBA(image.Rect(0, 0, 3, 2)))
+	if err := dst.Fill(bg); err != nil {
+		t.Fatal(err)
+	}
+	if err := dst.Draw(image.ZP, rotated); err != nil {
+		t.Fatal(err)
+	}
+	got := toNRGBA(t, dst)
+	if c := got.NRGBAAt(2, 1); c != bg {
+		t.Errorf("color keyed pixel mismatch; expected %v, got %v", bg, c)
+	}
+	c := src.NRGBAAt(2, 1)
+	want := color.NRGBA{R: c.R + bg.R, G: c.G + bg.G, B: c.B + bg.B, A: 0xFF}
+	if c := got.NRGBAAt(0, 0); c != want {
+		t.Errorf("additively blended pixel mismatch; expected %v, got %v", want, c)
+	}
+}
@@ -5,6 +5,11 @@
 // The library uses a small subset of the features provided by SDL version 2.0.
 // For the sake of simplicity support for multiple windows has intentionally
 // been left out.
+//
+// The window may be opened in headless mode, e.g. to run rendering code in CI
+// environments without a display server. A headless window is never shown, but
+// its image is fully functional. Use Snapshot to retrieve the rendered pixels:
+//    err := win.Open(640, 480, win.Options{Headless: true})
 package win
 
 // #cgo pkg-config: sdl2
@@ -36,39 +41,113 @@ const (
 	Resizeable WindowFlag = C.SDL_WINDOW_RESIZABLE
 	// FullScreen states that the window is in full screen mode.
 	FullScreen WindowFlag = C.SDL_WINDOW_FULLSCREEN
+	// FullScreenDesktop states that the window is in full screen mode at the
+	// resolution of the desktop. Contrary to FullScreen, the video mode of the
+	// display is left unchanged.
+	FullScreenDesktop WindowFlag = C.SDL_WINDOW_FULLSCREEN_DESKTOP
 )
 
 // w represents the graphics window which is opened through a call to Open. It
 // is this single window that is utilized throughout the entire library.
 var w *C.SDL_Window
 
-// Open opens a window with the specified dimensions and optional window flags.
-// Only one window can be open at the same time. It is this single window that
-// is utilized throughout the entire library. By default the window is not
-// resizeable.
+// An Option configures the window opened by Open. It is either a WindowFlag or
+// Options.
+type Option interface {
+	// apply applies the option to opts.
+	apply(opts *Options)
+}
+
+// apply adds the window flag to opts.
+func (flag WindowFlag) apply(opts *Options) {
+	opts.Flags |= flag
+}
+
+// Options specifies the initial configuration of the window opened by Open.
+type Options struct {
+	// The title of the window. The default title is "untitled".
+	Title string
+	// The icon of the window, or nil for the default icon.
+	Icon *Image
+	// The position of the top left corner of the window on the desktop, or nil
+	// to let the window manager decide. See also Display.Center.
+	Position *image.Point
+	// The window flags.
+	Flags WindowFlag
+	// Headless specifies whether to open the window without a display, using
+	// the offscreen or dummy video driver of SDL. It has no effect if the SDL
+	// video subsystem has already been initialized, e.g. by Displays.
+	Headless bool
+}
+
+// apply merges the options into opts.
+func (o Options) apply(opts *Options) {
+	if o.Title != "" {
+		opts.Title = o.Title
+	}
+	if o.Icon != nil {
+		opts.Icon = o.Icon
+	}
+	if o.Position != nil {
+		opts.Position = o.Position
+	}
+	opts.Flags |= o.Flags
+	opts.Headless = opts.Headless || o.Headless
+}
+
+// Open opens a window with the specified dimensions and optional window flags
+// and options. Only one window can be open at the same time. It is this single
+// window that is utilized throughout the entire library. By default the window
+// is not resizeable.
+//
+// The window is fully configured according to the provided options before it
+// is first shown.
 //
 // Note: The Close function must be called when finished using the window.
-func Open(width, height int, flags ...WindowFlag) (err error) {
+func Open(width, height int, options ...Option) (err error) {
 	if w != nil {
 		panic("win.Open: the window has already been opened.")
 	}
+	opts := Options{Title: "untitled"}
+	for _, option := range options {
+		option.apply(&opts)
+	}
+
 	// Initialize the SDL video subsystem.
-	if C.SDL_Init(C.SDL_INIT_VIDEO) != 0 {
+	if opts.Headless {
+		err = initHeadless()
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err != nil {
+				restoreDriver()
+			}
+		}()
+	} else if C.SDL_Init(C.SDL_INIT_VIDEO) != 0 {
 		return getError()
 	}
 
-	// Open the window.
-	var cFlags C.Uint32
-	for _, flag := range flags {
-		cFlags |= C.Uint32(flag)
-	}
-	title := C.CString("untitled")
+	// Open the window. It is kept hidden until configured.
+	cFlags := C.Uint32(opts.Flags) | C.SDL_WINDOW_HIDDEN
+	title := C.CString(opts.Title)
+	defer C.free(unsafe.Pointer(title))
 	x := C.int(C.SDL_WINDOWPOS_UNDEFINED)
 	y := C.int(C.SDL_WINDOWPOS_UNDEFINED)
+	if opts.Position != nil {
+		x = C.int(opts.Position.X)
+		y = C.int(opts.Position.Y)
+	}
 	w = C.SDL_CreateWindow(title, x, y, C.int(width), C.int(height), cFlags)
 	if w == nil {
 		return getError()
 	}
+	if opts.Icon != nil {
+		SetIcon(opts.Icon)
+	}
+	if !opts.Headless {
+		C.SDL_ShowWindow(w)
+	}
 
 	// Make sure the window surface is valid for updates.
 	s := C.SDL_GetWindowSurface(w)
@@ -79,11 +158,65 @@ func Open(width, height int, flags ...WindowFlag) (err error) {
 	return nil
 }
 
-// Close closes the window.
+// initHeadless initializes the SDL video subsystem using a video driver which
+// requires no display. The offscreen video driver is preferred, as the dummy
+// video driver is unavailable in some SDL builds and vice versa.
+//
+// The video driver hint is restored by restoreDriver, so that windows opened
+// later on use the default video driver.
+func initHeadless() (err error) {
+	// SDL_HINT_VIDEODRIVER is only defined by recent versions of SDL.
+	const hint = "SDL_VIDEODRIVER"
+	prev, ok := getHint(hint)
+	driverHint = &savedHint{value: prev, ok: ok}
+	for _, driver := range []string{"offscreen", "dummy"} {
+		setHint(hint, driver)
+		if C.SDL_Init(C.SDL_INIT_VIDEO) == 0 {
+			return nil
+		}
+	}
+	err = getError()
+	restoreDriver()
+	return err
+}
+
+// savedHint is the saved value of an SDL configuration hint, as returned by
+// getHint.
+type savedHint struct {
+	value string
+	ok    bool
+}
+
+// driverHint is the video driver hint prior to initHeadless, or nil if the hint
+// has not been changed.
+var driverHint *savedHint
+
+// restoreDriver restores the video driver hint changed by initHeadless.
+func restoreDriver() {
+	if driverHint == nil {
+		return
+	}
+	restoreHint("SDL_VIDEODRIVER", driverHint.value, driverHint.ok)
+	driverHint = nil
+}
+
+// Close closes the window. Only the SDL video subsystem started by Open is
+// quit, so that subsystems started by other packages, such as the game
+// controller subsystem of package controller, keep working when a window is
+// opened again.
 func Close() {
 	C.SDL_DestroyWindow(w)
 	w = nil
-	C.SDL_Quit()
+	queued = nil
+	// The video subsystem may also have been initialized to query displays
+	// before the window was opened.
+	for C.SDL_WasInit(C.SDL_INIT_VIDEO) != 0 {
+		C.SDL_QuitSubSystem(C.SDL_INIT_VIDEO)
+	}
+	if C.SDL_WasInit(C.SDL_INIT_EVERYTHING) == 0 {
+		C.SDL_Quit()
+	}
+	restoreDriver()
 }
 
 // SetTitle sets the title of the window.
@@ -91,7 +224,136 @@ func SetTitle(title string) {
 	C.SDL_SetWindowTitle(w, C.CString(title))
 }
 
+// SetIcon sets the icon of the window.
+func SetIcon(img *Image) {
+	C.SDL_SetWindowIcon(w, img.s)
+}
+
+// Position returns the position of the top left corner of the window on the
+// desktop.
+func Position() (pt image.Point) {
+	var x, y C.int
+	C.SDL_GetWindowPosition(w, &x, &y)
+	return image.Pt(int(x), int(y))
+}
+
+// SetPosition moves the top left corner of the window to the provided position
+// on the desktop.
+func SetPosition(pt image.Point) {
+	C.SDL_SetWindowPosition(w, C.int(pt.X), C.int(pt.Y))
+}
+
+// Size returns the width and height of the window.
+func Size() (width, height int) {
+	var cWidth, cHeight C.int
+	C.SDL_GetWindowSize(w, &cWidth, &cHeight)
+	return int(cWidth), int(cHeight)
+}
+
+// SetSize sets the width and height of the window.
+//
+// Note: Any image previously returned by Screen is invalidated.
+func SetSize(width, height int) {
+	C.SDL_SetWindowSize(w, C.int(width), C.int(height))
+}
+
+// MinSize returns the minimum width and height of the window.
+func MinSize() (width, height int) {
+	var cWidth, cHeight C.int
+	C.SDL_GetWindowMinimumSize(w, &cWidth, &cHeight)
+	return int(cWidth), int(cHeight)
+}
+
+// SetMinSize sets the minimum width and height of the window.
+func SetMinSize(width, height int) {
+	C.SDL_SetWindowMinimumSize(w, C.int(width), C.int(height))
+}
+
+// MaxSize returns the maximum width and height of the window.
+func MaxSize() (width, height int) {
+	var cWidth, cHeight C.int
+	C.SDL_GetWindowMaximumSize(w, &cWidth, &cHeight)
+	return int(cWidth), int(cHeight)
+}
+
+// SetMaxSize sets the maximum width and height of the window.
+func SetMaxSize(width, height int) {
+	C.SDL_SetWindowMaximumSize(w, C.int(width), C.int(height))
+}
+
+// Flags returns the window flags which are currently active.
+func Flags() (flags WindowFlag) {
+	return WindowFlag(C.SDL_GetWindowFlags(w))
+}
+
+// SetBordered adds or removes the border and decorations of the window.
+func SetBordered(bordered bool) {
+	C.SDL_SetWindowBordered(w, cBool(bordered))
+}
+
+// SetResizeable sets whether the window can be resized by the user.
+func SetResizeable(resizeable bool) {
+	C.SDL_SetWindowResizable(w, cBool(resizeable))
+}
+
+// SetAlwaysOnTop sets whether the window is kept above all other windows.
+func SetAlwaysOnTop(onTop bool) {
+	C.SDL_SetWindowAlwaysOnTop(w, cBool(onTop))
+}
+
+// Opacity returns the opacity of the window, in the range from 0.0 (fully
+// transparent) to 1.0 (fully opaque).
+func Opacity() (opacity float64, err error) {
+	var cOpacity C.float
+	if C.SDL_GetWindowOpacity(w, &cOpacity) != 0 {
+		return 0, getError()
+	}
+	return float64(cOpacity), nil
+}
+
+// SetOpacity sets the opacity of the window, in the range from 0.0 (fully
+// transparent) to 1.0 (fully opaque).
+func SetOpacity(opacity float64) (err error) {
+	if C.SDL_SetWindowOpacity(w, C.float(opacity)) != 0 {
+		return getError()
+	}
+	return nil
+}
+
+// Maximize maximizes the window.
+func Maximize() {
+	C.SDL_MaximizeWindow(w)
+}
+
+// Minimize minimizes the window.
+func Minimize() {
+	C.SDL_MinimizeWindow(w)
+}
+
+// Restore restores the size and position of a maximized or minimized window.
+func Restore() {
+	C.SDL_RestoreWindow(w)
+}
+
+// SetFullScreen switches the window between windowed mode and full screen
+// mode. The mode is either FullScreen, for exclusive full screen mode,
+// FullScreenDesktop, for full screen mode at the resolution of the desktop, or
+// 0 for windowed mode.
+//
+// Note: Any image previously returned by Screen is invalidated.
+func SetFullScreen(mode WindowFlag) (err error) {
+	mode &= FullScreen | FullScreenDesktop
+	if C.SDL_SetWindowFullscreen(w, C.Uint32(mode)) != 0 {
+		return getError()
+	}
+	return nil
+}
+
 // Screen returns the image associated with the window.
+//
+// Note: The image is invalidated whenever the size of the window changes, as
+// signaled by a we.Resize event. Call Screen again to retrieve an image of the
+// new size.
 func Screen() (screen *Image, err error) {
 	screen = new(Image)
 	screen.s = C.SDL_GetWindowSurface(w)
@@ -103,12 +365,23 @@ func Screen() (screen *Image, err error) {
 	return screen, nil
 }
 
-// Update copies the entire window image onto the screen.
+// Snapshot returns a copy of the current contents of the window image. It is
+// typically used in tests to assert on rendered pixels; see Options.Headless.
+func Snapshot() (img *image.NRGBA, err error) {
+	s := C.SDL_GetWindowSurface(w)
+	if s == nil {
+		return nil, getError()
+	}
+	return surfaceNRGBA(s)
+}
+
+// Update copies the entire window image onto the screen. The window image is
+// also captured by any active Recorder.
 func Update() (err error) {
 	if C.SDL_UpdateWindowSurface(w) != 0 {
 		return getError()
 	}
-	return nil
+	return captureFrames()
 }
 
 // UpdateRects copies a portion of the window image onto the screen as specified
@@ -123,7 +396,7 @@ func UpdateRects(rects []image.Rectangle) (err error) {
 	if C.SDL_UpdateWindowSurfaceRects(w, cRects, C.int(len(rects))) != 0 {
 		return getError()
 	}
-	return nil
+	return captureFrames()
 }
 
 // Draw draws the entire src image onto the window starting at the destination
@@ -145,3 +418,14 @@ func DrawRect(dr image.Rectangle, src *Image, sp image.Point) (err error) {
 	}
 	return dst.DrawRect(dr, src, sp)
 }
+
+// DrawScaled fills the destination rectangle dr of the window with the pixels
+// of the source rectangle sr of the src image, scaled to fit. The entire src
+// image is used if sr is empty.
+func DrawScaled(dr image.Rectangle, src *Image, sr image.Rectangle) (err error) {
+	dst, err := Screen()
+	if err != nil {
+		return err
+	}
+	return dst.DrawScaled(dr, src, sr)
+}
@@ -0,0 +1,36 @@
+package win
+
+import (
+	"os"
+	"testing"
+)
+
+// openHeadless opens a headless window of the specified dimensions for the
+// duration of the test.
+func openHeadless(t testing.TB, width, height int) {
+	t.Helper()
+	err := Open(width, height, Options{Headless: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(Close)
+}
+
+func TestHeadlessRestoresDriver(t *testing.T) {
+	if os.Getenv("SDL_VIDEODRIVER") != "" {
+		t.Skip("video driver hint overridden by environment")
+	}
+	for _, want := range []savedHint{{}, {value: "x11", ok: true}} {
+		restoreHint("SDL_VIDEODRIVER", want.value, want.ok)
+		err := Open(64, 48, Options{Headless: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		Close()
+		value, ok := getHint("SDL_VIDEODRIVER")
+		if got := (savedHint{value: value, ok: ok}); got != want {
+			t.Errorf("video driver hint mismatch; expected %+v, got %+v", want, got)
+		}
+	}
+	restoreHint("SDL_VIDEODRIVER", "", false)
+}
@@ -0,0 +1,89 @@
+package wintest
+
+import (
+	"image"
+	"image/color"
+)
+
+// maxDelta is the maximum possible perceptual difference between two colors,
+// as returned by colorDelta.
+const maxDelta = 35215.0
+
+// Diff compares the images a and b, and returns the number of pixels whose
+// perceptual difference exceeds threshold, which ranges from 0.0 (exact match)
+// to 1.0. It also returns a diff image, in which differing pixels are red and
+// equal pixels are faded grayscale versions of the pixels of a. Pixels outside
+// of the bounds of either image are always considered to differ.
+func Diff(a, b image.Image, threshold float64) (diffImg *image.NRGBA, n int) {
+	ar, br := a.Bounds(), b.Bounds()
+	// The images are compared with their top left corners aligned.
+	width, height := maxInt(ar.Dx(), br.Dx()), maxInt(ar.Dy(), br.Dy())
+	diffImg = image.NewNRGBA(image.Rect(0, 0, width, height))
+	red := color.NRGBA{R: 0xFF, A: 0xFF}
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			pa := image.Pt(ar.Min.X+x, ar.Min.Y+y)
+			pb := image.Pt(br.Min.X+x, br.Min.Y+y)
+			if !pa.In(ar) || !pb.In(br) {
+				diffImg.SetNRGBA(x, y, red)
+				n++
+				continue
+			}
+			ca := color.NRGBAModel.Convert(a.At(pa.X, pa.Y)).(color.NRGBA)
+			cb := color.NRGBAModel.Convert(b.At(pb.X, pb.Y)).(color.NRGBA)
+			if colorDelta(ca, cb) > threshold*threshold*maxDelta {
+				diffImg.SetNRGBA(x, y, red)
+				n++
+				continue
+			}
+			// Fade equal pixels to make the differing pixels stand out.
+			v := uint8(0xFF - (0xFF-gray(ca))/10)
+			diffImg.SetNRGBA(x, y, color.NRGBA{R: v, G: v, B: v, A: 0xFF})
+		}
+	}
+	return diffImg, n
+}
+
+// colorDelta returns the squared perceptual difference between two colors,
+// measured in the YIQ color space after blending them onto a white background.
+// The difference ranges from 0 to maxDelta.
+func colorDelta(c1, c2 color.NRGBA) float64 {
+	if c1 == c2 {
+		return 0
+	}
+	y1, i1, q1 := yiq(blend(c1))
+	y2, i2, q2 := yiq(blend(c2))
+	dy, di, dq := y1-y2, i1-i2, q1-q2
+	return 0.5053*dy*dy + 0.299*di*di + 0.1957*dq*dq
+}
+
+// blend returns the color channels of c blended onto a white background.
+func blend(c color.NRGBA) (r, g, b float64) {
+	a := float64(c.A) / 0xFF
+	r = 0xFF + (float64(c.R)-0xFF)*a
+	g = 0xFF + (float64(c.G)-0xFF)*a
+	b = 0xFF + (float64(c.B)-0xFF)*a
+	return r, g, b
+}
+
+// yiq converts the provided RGB color channels to the YIQ color space.
+func yiq(r, g, b float64) (y, i, q float64) {
+	y = 0.29889531*r + 0.58662247*g + 0.11448223*b
+	i = 0.59597799*r - 0.27417610*g - 0.32180189*b
+	q = 0.21147017*r - 0.52261711*g + 0.31114694*b
+	return y, i, q
+}
+
+// gray returns the luminance of c blended onto a white background.
+func gray(c color.NRGBA) uint8 {
+	y, _, _ := yiq(blend(c))
+	return uint8(y)
+}
+
+// maxInt returns the larger of a and b.
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
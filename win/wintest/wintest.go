@@ -0,0 +1,134 @@
+// Package wintest provides utility functions for testing rendering code based
+// on package win, by comparing rendered images against golden images.
+//
+// Golden images are regenerated by running the tests with the -wintest.update
+// flag:
+//    go test -wintest.update
+package wintest
+
+import (
+	"flag"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mewkiz/pkg/imgutil"
+	"github.com/mewmew/sdl/win"
+)
+
+// Update specifies whether to regenerate golden images rather than comparing
+// against them. It is set by the -wintest.update flag, which is namespaced so
+// as not to collide with flags of the test packages. Test packages which define
+// their own -update flag may forward it from TestMain, after flag.Parse:
+//    wintest.Update = *update
+var Update bool
+
+func init() {
+	flag.BoolVar(&Update, "wintest.update", false, "update golden images of package wintest")
+}
+
+// Tolerance specifies how much a rendered image may differ from its golden
+// image.
+type Tolerance struct {
+	// Threshold is the maximum perceptual difference between two pixels for
+	// them to be considered equal, in the range from 0.0 (exact match) to 1.0.
+	Threshold float64
+	// MaxDiff is the maximum number of pixels which may differ.
+	MaxDiff int
+}
+
+// DefaultTolerance is the tolerance used by AssertGolden. It permits subtle
+// differences caused by anti-aliasing and rounding, but no differing pixels.
+var DefaultTolerance = Tolerance{
+	Threshold: 0.1,
+}
+
+// AssertGolden compares img against the golden image stored at goldenPath,
+// using DefaultTolerance. See AssertGoldenTolerance.
+func AssertGolden(t testing.TB, img image.Image, goldenPath string) {
+	t.Helper()
+	AssertGoldenTolerance(t, img, goldenPath, DefaultTolerance)
+}
+
+// AssertGoldenTolerance compares img against the golden image stored at
+// goldenPath, and reports a test failure if they differ by more than tol. On
+// failure the rendered image and a diff image, highlighting the differing
+// pixels in red, are written to a "failed" directory next to the golden image.
+//
+// The golden image is overwritten with img if Update is set.
+func AssertGoldenTolerance(t testing.TB, img image.Image, goldenPath string, tol Tolerance) {
+	t.Helper()
+	if Update {
+		err := os.MkdirAll(filepath.Dir(goldenPath), 0755)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = writePNG(goldenPath, img)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	golden, err := imgutil.ReadFile(goldenPath)
+	if err != nil {
+		t.Fatalf("unable to read golden image; run with -wintest.update to create it: %v", err)
+	}
+	diffImg, n := Diff(img, golden, tol.Threshold)
+	if n <= tol.MaxDiff {
+		return
+	}
+
+	// Store the rendered image and the diff image for inspection.
+	failedDir := filepath.Join(filepath.Dir(goldenPath), "failed")
+	name := filepath.Base(goldenPath)
+	ext := filepath.Ext(name)
+	gotPath := filepath.Join(failedDir, name)
+	diffPath := filepath.Join(failedDir, name[:len(name)-len(ext)]+"_diff"+ext)
+	if err := os.MkdirAll(failedDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := writePNG(gotPath, img); err != nil {
+		t.Fatal(err)
+	}
+	if err := writePNG(diffPath, diffImg); err != nil {
+		t.Fatal(err)
+	}
+	t.Errorf("image differs from golden image %q in %d pixels (max %d); see %q and %q", goldenPath, n, tol.MaxDiff, gotPath, diffPath)
+}
+
+// OpenHeadless opens a headless window of the specified dimensions, which is
+// closed automatically when the test finishes.
+func OpenHeadless(t testing.TB, width, height int) {
+	t.Helper()
+	err := win.Open(width, height, win.Options{Headless: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(win.Close)
+}
+
+// Snapshot returns a copy of the current contents of the window image.
+func Snapshot(t testing.TB) *image.NRGBA {
+	t.Helper()
+	img, err := win.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return img
+}
+
+// writePNG writes img to the provided PNG file.
+func writePNG(pngPath string, img image.Image) (err error) {
+	f, err := os.Create(pngPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	err = png.Encode(f, img)
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,148 @@
+package wintest_test
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/mewmew/sdl/font"
+	"github.com/mewmew/sdl/font/fontutil"
+	"github.com/mewmew/sdl/win"
+	"github.com/mewmew/sdl/win/wintest"
+)
+
+// fontPath is the path to the TTF font used by the text rendering tests.
+const fontPath = "../../examples/boxes/data/DejaVuSerif.ttf"
+
+// fontTolerance permits small differences in glyph rasterization between
+// versions of FreeType.
+var fontTolerance = wintest.Tolerance{
+	Threshold: 0.1,
+	MaxDiff:   40,
+}
+
+// testImage returns an asymmetric test image of the specified dimensions, with
+// a distinct color in each quadrant and a semi-transparent border.
+func testImage(t *testing.T, width, height int) *win.Image {
+	t.Helper()
+	src := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			c := color.NRGBA{R: uint8(x * 255 / width), G: uint8(y * 255 / height), A: 0xFF}
+			if x >= width/2 {
+				c.B = 0xFF
+			}
+			if x == 0 || y == 0 || x == width-1 || y == height-1 {
+				c = color.NRGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0x80}
+			}
+			src.SetNRGBA(x, y, c)
+		}
+	}
+	img, err := win.ReadImage(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(img.Free)
+	return img
+}
+
+// clearScreen fills the window with a dark background.
+func clearScreen(t *testing.T) {
+	t.Helper()
+	screen, err := win.Screen()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := screen.Fill(color.NRGBA{R: 0x20, G: 0x20, B: 0x40, A: 0xFF}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// loadFont loads the test font of the specified size in blended mode.
+func loadFont(t *testing.T, size int) *font.Font {
+	t.Helper()
+	f, err := font.Load(fontPath, size)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(f.Free)
+	f.SetColor(color.White)
+	f.SetMode(font.Blended)
+	return f
+}
+
+func TestDraw(t *testing.T) {
+	wintest.OpenHeadless(t, 64, 48)
+	clearScreen(t)
+	src := testImage(t, 24, 16)
+	if err := win.Draw(image.Pt(4, 6), src); err != nil {
+		t.Fatal(err)
+	}
+	// Images are drawn onto other images as well as onto the window.
+	dst, err := win.NewImage(20, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dst.Free()
+	if err := dst.Draw(image.Pt(-8, 8), src); err != nil {
+		t.Fatal(err)
+	}
+	if err := win.Draw(image.Pt(40, 24), dst); err != nil {
+		t.Fatal(err)
+	}
+	wintest.AssertGolden(t, wintest.Snapshot(t), "testdata/draw.png")
+}
+
+func TestDrawRectClip(t *testing.T) {
+	wintest.OpenHeadless(t, 64, 48)
+	clearScreen(t)
+	src := testImage(t, 24, 16)
+	// The destination rectangles extend beyond the top left and bottom right
+	// corners of the window, and the source rectangle beyond the source image.
+	rects := []struct {
+		dr image.Rectangle
+		sp image.Point
+	}{
+		{dr: image.Rect(-6, -4, 14, 10), sp: image.Pt(2, 0)},
+		{dr: image.Rect(50, 38, 74, 54), sp: image.Pt(0, 0)},
+		{dr: image.Rect(20, 20, 40, 36), sp: image.Pt(12, 8)},
+	}
+	for _, r := range rects {
+		if err := win.DrawRect(r.dr, src, r.sp); err != nil {
+			t.Fatal(err)
+		}
+	}
+	wintest.AssertGolden(t, wintest.Snapshot(t), "testdata/draw_rect_clip.png")
+}
+
+func TestFontRender(t *testing.T) {
+	wintest.OpenHeadless(t, 160, 32)
+	clearScreen(t)
+	f := loadFont(t, 18)
+	img, err := f.Render("Hello, world!")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer img.Free()
+	if err := win.Draw(image.Pt(4, 4), img); err != nil {
+		t.Fatal(err)
+	}
+	wintest.AssertGoldenTolerance(t, wintest.Snapshot(t), "testdata/font_render.png", fontTolerance)
+}
+
+func TestBoxRender(t *testing.T) {
+	wintest.OpenHeadless(t, 128, 136)
+	clearScreen(t)
+	f := loadFont(t, 14)
+	box := fontutil.NewBox(f, 120)
+	box.SetSpacing(2)
+	img, err := box.Render("The quick brown fox jumps over the lazy dog.\n\nPack my box with five dozen liquor jugs.")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer img.Free()
+	if err := win.Draw(image.Pt(4, 2), img); err != nil {
+		t.Fatal(err)
+	}
+	wintest.AssertGoldenTolerance(t, wintest.Snapshot(t), "testdata/box_render.png", fontTolerance)
+}
@@ -0,0 +1,93 @@
+package win
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"time"
+)
+
+// A y4mEncoder encodes frames as raw YUV 4:2:0 video in the YUV4MPEG2 format.
+//
+// The dimensions of the video are given by the first frame; subsequent frames
+// are cropped or padded with black to fit.
+type y4mEncoder struct {
+	// Output file or stream.
+	f io.WriteCloser
+	// Buffered writer of the output file.
+	w *bufio.Writer
+	// The frame rate of the video.
+	fps int
+	// The dimensions of the video.
+	width, height int
+	// Y, Cb and Cr planes of the current frame.
+	y, cb, cr []byte
+}
+
+// encode writes the frame to the video stream. The frame duration is ignored,
+// as the video has a constant frame rate.
+func (enc *y4mEncoder) encode(img *image.NRGBA, d time.Duration) (err error) {
+	if enc.w == nil {
+		bounds := img.Bounds()
+		enc.width, enc.height = bounds.Dx(), bounds.Dy()
+		enc.w = bufio.NewWriter(enc.f)
+		// C420jpeg specifies full range 4:2:0 chroma subsampling with chroma
+		// samples centered between luma samples, as produced by
+		// color.RGBToYCbCr.
+		_, err = fmt.Fprintf(enc.w, "YUV4MPEG2 W%d H%d F%d:1 Ip A1:1 C420jpeg\n", enc.width, enc.height, enc.fps)
+		if err != nil {
+			return err
+		}
+		cw, ch := (enc.width+1)/2, (enc.height+1)/2
+		enc.y = make([]byte, enc.width*enc.height)
+		enc.cb = make([]byte, cw*ch)
+		enc.cr = make([]byte, cw*ch)
+	}
+
+	// Convert the frame to Y'CbCr. Each chroma sample is the average of the
+	// corresponding 2x2 block of pixels.
+	cw := (enc.width + 1) / 2
+	cbSum := make([]int, len(enc.cb))
+	crSum := make([]int, len(enc.cr))
+	count := make([]int, len(enc.cb))
+	min := img.Bounds().Min
+	for y := 0; y < enc.height; y++ {
+		for x := 0; x < enc.width; x++ {
+			var r, g, b uint8
+			if pt := image.Pt(min.X+x, min.Y+y); pt.In(img.Bounds()) {
+				i := img.PixOffset(pt.X, pt.Y)
+				r, g, b = img.Pix[i], img.Pix[i+1], img.Pix[i+2]
+			}
+			yy, cb, cr := color.RGBToYCbCr(r, g, b)
+			enc.y[y*enc.width+x] = yy
+			j := (y/2)*cw + x/2
+			cbSum[j] += int(cb)
+			crSum[j] += int(cr)
+			count[j]++
+		}
+	}
+	for j := range enc.cb {
+		enc.cb[j] = uint8(cbSum[j] / count[j])
+		enc.cr[j] = uint8(crSum[j] / count[j])
+	}
+
+	enc.w.WriteString("FRAME\n")
+	enc.w.Write(enc.y)
+	enc.w.Write(enc.cb)
+	_, err = enc.w.Write(enc.cr)
+	return err
+}
+
+// close flushes the video stream and closes the output file.
+func (enc *y4mEncoder) close() (err error) {
+	defer enc.f.Close()
+	if enc.w != nil {
+		err = enc.w.Flush()
+		if err != nil {
+			return err
+		}
+	}
+	return enc.f.Close()
+}